@@ -0,0 +1,199 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/manashmandal/litecov/internal/badge"
+	"github.com/manashmandal/litecov/internal/coverage"
+	"github.com/manashmandal/litecov/internal/diff"
+	"github.com/manashmandal/litecov/internal/github"
+	"github.com/manashmandal/litecov/internal/parser"
+	"github.com/manashmandal/litecov/internal/paths"
+	"github.com/manashmandal/litecov/internal/status"
+	"github.com/manashmandal/litecov/internal/storage"
+)
+
+// badgesDir is where badges are committed on the data branch.
+const badgesDir = "badges"
+
+// runBadge implements "litecov badge", which writes SVG badges for the
+// project, the patch and each component.
+func runBadge(args []string) error {
+	fs := flag.NewFlagSet("badge", flag.ExitOnError)
+	coverageFile := fs.String("coverage-file", "", "Path to coverage report file")
+	format := fs.String("format", "auto", "Coverage format: auto, lcov, cobertura")
+	label := fs.String("label", badge.DefaultLabel, "Label of the project badge")
+	patchLabel := fs.String("patch-label", "patch", "Label of the patch badge")
+	buckets := fs.String("buckets", "", "Coverage ranges as \"min:colour\", e.g. \"90:brightgreen, 70:yellow, 0:red\"")
+	diffFile := fs.String("diff", "", "Unified diff to compute the patch badge from (\"-\" for stdin)")
+	components := fs.String("components", "", "Newline separated components as \"name[@target]: pattern, pattern\"")
+	include := fs.String("include", "", "Comma or newline separated glob patterns of files to include")
+	exclude := fs.String("exclude", "", "Comma or newline separated glob patterns of files to exclude")
+	outputDir := fs.String("output-dir", ".", "Directory the badges are written to")
+	storageBackend := fs.String("storage", "", "Set to \"branch\" to commit badges to the data branch instead")
+	storageBranch := fs.String("storage-branch", storage.DefaultBranch, "Branch used by the branch storage")
+	apiURL := fs.String("api-url", "", "GitHub API base URL (defaults to GITHUB_API_URL)")
+	fs.Parse(args)
+
+	if *coverageFile == "" {
+		if *coverageFile = detectCoverageFile(); *coverageFile == "" {
+			return fmt.Errorf("no coverage file found, specify with -coverage-file")
+		}
+	}
+	report, err := loadReport(*coverageFile, *format)
+	if err != nil {
+		return err
+	}
+	report.Filter(paths.NewFilter(paths.SplitPatterns(*include), paths.SplitPatterns(*exclude), true).Match)
+
+	bucketList, err := coverage.ParseBuckets(*buckets)
+	if err != nil {
+		return err
+	}
+	componentList, err := status.ParseComponents(*components)
+	if err != nil {
+		return err
+	}
+
+	var patch *coverage.Patch
+	if *diffFile != "" {
+		var data []byte
+		if *diffFile == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(*diffFile)
+		}
+		if err != nil {
+			return err
+		}
+		patch = coverage.NewPatch(report, diff.ParseAddedLines(string(data)))
+	}
+
+	files := renderBadges(report, patch, componentList, badgeLabels{Project: *label, Patch: *patchLabel}, bucketList)
+
+	switch *storageBackend {
+	case "":
+		for _, name := range sortedNames(files) {
+			path := filepath.Join(*outputDir, name)
+			if err := os.WriteFile(path, files[name], 0o644); err != nil {
+				return err
+			}
+			fmt.Printf("Wrote %s\n", path)
+		}
+		return nil
+	case "branch":
+		owner, repo, ok := strings.Cut(os.Getenv("GITHUB_REPOSITORY"), "/")
+		if !ok {
+			return fmt.Errorf("GITHUB_REPOSITORY is required for branch storage")
+		}
+		gh := github.NewClientWithBaseURL(os.Getenv("GITHUB_TOKEN"), owner, repo,
+			firstNonEmpty(*apiURL, os.Getenv("GITHUB_API_URL")))
+		branch := storage.NewBranch(gh)
+		branch.Name = *storageBranch
+		if err := commitBadges(branch, files); err != nil {
+			return err
+		}
+		fmt.Printf("Committed %d badges to %s\n", len(files), branch.Name)
+		return nil
+	default:
+		return fmt.Errorf("unknown storage: %s", *storageBackend)
+	}
+}
+
+// loadReport parses a coverage file, detecting its format when format is "auto".
+func loadReport(path, format string) (*coverage.Report, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if format == "auto" {
+		if format, err = parser.DetectFormat(f); err != nil {
+			return nil, err
+		}
+		if _, err := f.Seek(0, 0); err != nil {
+			return nil, err
+		}
+	}
+	p, err := parser.GetParserWithPath(format, path)
+	if err != nil {
+		return nil, err
+	}
+	return p.Parse(f)
+}
+
+// badgeLabels holds the label text of the project and patch badges.
+// Component badges are labelled with the component name.
+type badgeLabels struct {
+	Project string
+	Patch   string
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// renderBadges returns SVG badges keyed by file name: coverage.svg for the
+// project, patch.svg when patch has coverable lines, and
+// component-<name>.svg, so no component can replace the other two.
+func renderBadges(report *coverage.Report, patch *coverage.Patch, components []status.Component, labels badgeLabels, buckets coverage.Buckets) map[string][]byte {
+	files := map[string][]byte{
+		"coverage.svg": badge.Coverage(labels.Project, report.Coverage, buckets).SVG(),
+	}
+	if patch != nil && patch.Total > 0 {
+		files["patch.svg"] = badge.Coverage(labels.Patch, patch.Coverage(), buckets).SVG()
+	}
+	for _, c := range components {
+		filter := &paths.Filter{Include: c.Patterns}
+		subset := report.Subset(filter.Match)
+		name := "component-" + unsafeFileChars.ReplaceAllString(c.Name, "-") + ".svg"
+		files[name] = badge.Coverage(c.Name, subset.Coverage, buckets).SVG()
+	}
+	return files
+}
+
+// commitBadges commits badges to the badges directory of the data branch.
+func commitBadges(branch *storage.Branch, files map[string][]byte) error {
+	entries := make(map[string][]byte, len(files))
+	for name, data := range files {
+		entries[badgesDir+"/"+name] = data
+	}
+	return branch.Put(entries, "Update coverage badges")
+}
+
+// storeBadges writes badges next to the reports of store: committed to the
+// data branch, or into the badges directory of the storage directory.
+func storeBadges(store storage.Storage, files map[string][]byte) error {
+	switch s := store.(type) {
+	case *storage.Branch:
+		return commitBadges(s, files)
+	case *storage.Dir:
+		dir := filepath.Join(s.Path, badgesDir)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+		for name, data := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("storage does not support badges")
+	}
+}
+
+func sortedNames(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
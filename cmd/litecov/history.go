@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/manashmandal/litecov/internal/comment"
+	"github.com/manashmandal/litecov/internal/github"
+	"github.com/manashmandal/litecov/internal/storage"
+)
+
+// runHistory implements "litecov history", which prints the coverage trend
+// of the last default branch commits recorded in storage.
+func runHistory(args []string) error {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	n := fs.Int("n", trendLength, "Number of commits to show")
+	file := fs.String("file", "", "Show the trend of a single file instead of the project")
+	storageBackend := fs.String("storage", "", "Where history is kept: \"branch\" (orphan branch) or \"dir\" (local directory)")
+	storageBranch := fs.String("storage-branch", storage.DefaultBranch, "Branch used by the branch storage")
+	storageDir := fs.String("storage-dir", storage.DefaultDir, "Directory used by the dir storage")
+	apiURL := fs.String("api-url", "", "GitHub API base URL (defaults to GITHUB_API_URL)")
+	fs.Parse(args)
+
+	if *storageBackend == "" {
+		*storageBackend = firstNonEmpty(os.Getenv("INPUT_STORAGE"), "dir")
+	}
+
+	var h storage.History
+	switch *storageBackend {
+	case "branch":
+		owner, repo, ok := strings.Cut(os.Getenv("GITHUB_REPOSITORY"), "/")
+		if !ok {
+			return fmt.Errorf("GITHUB_REPOSITORY is required for branch storage")
+		}
+		gh := github.NewClientWithBaseURL(os.Getenv("GITHUB_TOKEN"), owner, repo,
+			firstNonEmpty(*apiURL, os.Getenv("GITHUB_API_URL")))
+		branch := storage.NewBranch(gh)
+		branch.Name = *storageBranch
+		h = branch
+	case "dir":
+		h = storage.NewDir(*storageDir)
+	default:
+		return fmt.Errorf("unknown storage: %s", *storageBackend)
+	}
+
+	entries, err := h.Recent(*n)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("No coverage history recorded")
+		return nil
+	}
+	printHistory(os.Stdout, entries, *file)
+	return nil
+}
+
+// printHistory writes one row per entry with the change from the previous
+// entry, followed by a sparkline of the whole series.
+func printHistory(w io.Writer, entries []storage.HistoryEntry, file string) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COMMIT\tDATE\tCOVERAGE\tCHANGE\tLINES")
+
+	var values []float64
+	for _, e := range entries {
+		covered, total, pct := e.Covered, e.Total, e.Coverage
+		if file != "" {
+			f, ok := e.File(file)
+			if !ok {
+				fmt.Fprintf(tw, "%s\t%s\t-\t\t\n", shortSHA(e.SHA), e.Time.Format("2006-01-02"))
+				continue
+			}
+			covered, total, pct = f.Covered, f.Total, f.Coverage()
+		}
+
+		change := ""
+		if len(values) > 0 {
+			change = fmt.Sprintf("%+.2f%%", pct-values[len(values)-1])
+		}
+		values = append(values, pct)
+		fmt.Fprintf(tw, "%s\t%s\t%.2f%%\t%s\t%d/%d\n",
+			shortSHA(e.SHA), e.Time.Format("2006-01-02"), pct, change, covered, total)
+	}
+	tw.Flush()
+
+	if len(values) > 1 {
+		fmt.Fprintf(w, "\nTrend: %s\n", comment.Sparkline(values))
+	}
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
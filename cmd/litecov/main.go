@@ -1,28 +1,89 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/manashmandal/litecov/internal/badge"
 	"github.com/manashmandal/litecov/internal/comment"
 	"github.com/manashmandal/litecov/internal/coverage"
+	"github.com/manashmandal/litecov/internal/diff"
 	"github.com/manashmandal/litecov/internal/github"
+	"github.com/manashmandal/litecov/internal/htmlreport"
 	"github.com/manashmandal/litecov/internal/parser"
 	"github.com/manashmandal/litecov/internal/paths"
+	"github.com/manashmandal/litecov/internal/review"
+	"github.com/manashmandal/litecov/internal/status"
+	"github.com/manashmandal/litecov/internal/storage"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "history" {
+		if err := runHistory(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read coverage history: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "badge" {
+		if err := runBadge(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to create badges: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	coverageFile := flag.String("coverage-file", "", "Path to coverage report file")
 	format := flag.String("format", "auto", "Coverage format: auto, lcov, cobertura")
 	showFiles := flag.String("show-files", "changed", "Files to show: all, changed, threshold:N, worst:N")
+	directoryDepth := flag.Int("directory-depth", 0, "Roll up shown files into a table of directories this many levels deep (0 disables)")
+	directorySort := flag.String("directory-sort", coverage.SortByName, "Order of the directory table: name, coverage or change")
+	indirectThreshold := flag.Float64("indirect-threshold", 0, "Minimum coverage change of an unchanged file to list it as an indirect change, in percentage points")
 	threshold := flag.Float64("threshold", 0, "Minimum coverage threshold for passing status")
+	fileThreshold := flag.Float64("file-threshold", 0, "Minimum coverage of any single file")
+	thresholdRules := flag.String("threshold-rules", "", "Comma or newline separated per-directory minimums, e.g. \"internal/billing/**: 90\"")
+	maxCoverageDrop := flag.String("max-coverage-drop", "", "Maximum allowed project coverage drop versus base, in percentage points")
+	maxFileDrop := flag.String("max-file-drop", "", "Maximum allowed coverage drop of a changed file versus base, in percentage points")
+	newFileMinimum := flag.String("new-file-minimum", "", "Minimum coverage of files that are new versus base")
+	patchTarget := flag.Float64("patch-target", 0, "Minimum coverage of lines added in the pull request")
+	components := flag.String("components", "", "Newline separated components as \"name[@target]: pattern, pattern\"")
+	statusContext := flag.String("status-context", status.DefaultContext, "Context of the project commit status and prefix of the others")
+	projectStatus := flag.String("project-status", "", "Context name of the project commit status (default: status-context)")
+	patchStatus := flag.String("patch-status", "", "Context name of the patch commit status (default: status-context/patch)")
+	informational := flag.Bool("informational", false, "Always report successful statuses and never fail the job")
 	title := flag.String("title", "Coverage Report", "Comment title")
+	commentTemplate := flag.String("comment-template", "", "Go text/template file overriding the comment or its sections")
 	annotations := flag.Bool("annotations", false, "Output GitHub annotations for uncovered lines")
 	baseCoverageFile := flag.String("base-coverage-file", "", "Path to base branch coverage file for comparison")
-	baseBranch := flag.String("base-branch", "main", "Base branch name for comparison display")
+	baseBranch := flag.String("base-branch", "", "Base branch name for comparison display (default: the pull request's base branch or main)")
+	include := flag.String("include", "", "Comma or newline separated glob patterns of files to include")
+	exclude := flag.String("exclude", "", "Comma or newline separated glob patterns of files to exclude")
+	defaultExcludes := flag.Bool("default-excludes", true, "Exclude vendored and well-known generated files by default")
+	languagesFile := flag.String("languages", "", "Path to a JSON file with additional language definitions")
+	ignoreMarkers := flag.Bool("ignore-markers", true, "Honour litecov:ignore markers in source files")
+	checks := flag.Bool("checks", false, "Report results as a check run with annotations instead of workflow commands")
+	jobSummary := flag.Bool("job-summary", true, "Append the full coverage report to the Actions job summary")
+	htmlReport := flag.String("html-report", "", "Directory to write a static HTML coverage report to")
+	reviewComments := flag.Bool("review-comments", false, "Post a pull request review with comments on uncovered added lines")
+	maxReviewComments := flag.Int("max-review-comments", review.DefaultMaxComments, "Maximum number of comments in a single review")
+	apiURL := flag.String("api-url", "", "GitHub API URL (default $GITHUB_API_URL or https://api.github.com)")
+	serverURL := flag.String("server-url", "", "GitHub server URL used for links (default $GITHUB_SERVER_URL or https://github.com)")
+	caBundle := flag.String("ca-bundle", "", "Path to a PEM file with extra CA certificates for the GitHub API")
+	appID := flag.Int64("app-id", 0, "GitHub App ID to authenticate as instead of GITHUB_TOKEN")
+	appInstallationID := flag.Int64("app-installation-id", 0, "GitHub App installation ID (looked up from the repository when unset)")
+	appPrivateKey := flag.String("app-private-key", "", "GitHub App private key, as PEM or a path to a PEM file")
+	storageBackend := flag.String("storage", "", "Where to keep base reports: \"branch\" (orphan branch) or \"dir\" (local directory)")
+	storageBranch := flag.String("storage-branch", storage.DefaultBranch, "Branch used by the branch storage")
+	storageDir := flag.String("storage-dir", storage.DefaultDir, "Directory used by the dir storage")
+	badges := flag.Bool("badges", false, "Store SVG coverage badges alongside reports on default branch pushes")
+	coverageBuckets := flag.String("coverage-buckets", "", "Coverage ranges as \"min:colour[:emoji[:label]]\", e.g. \"90:brightgreen, 70:yellow, 0:red\"")
+	indicators := flag.String("indicators", "emoji", "Mark coverage ranges with \"emoji\" or plain \"text\" labels")
+	excludeGenerated := flag.Bool("exclude-generated", true, "Exclude files marked as generated by headers or .gitattributes")
 	flag.Parse()
 
 	// Environment variable overrides for GitHub Action
@@ -32,13 +93,136 @@ func main() {
 	if envBaseBranch := os.Getenv("INPUT_BASE_BRANCH"); envBaseBranch != "" {
 		*baseBranch = envBaseBranch
 	}
+	if *fileThreshold == 0 {
+		if v, err := strconv.ParseFloat(os.Getenv("INPUT_FILE_THRESHOLD"), 64); err == nil {
+			*fileThreshold = v
+		}
+	}
+	if *indirectThreshold == 0 {
+		if v, err := strconv.ParseFloat(os.Getenv("INPUT_INDIRECT_THRESHOLD"), 64); err == nil {
+			*indirectThreshold = v
+		}
+	}
+	if *thresholdRules == "" {
+		*thresholdRules = os.Getenv("INPUT_THRESHOLD_RULES")
+	}
+	if v, err := strconv.Atoi(os.Getenv("INPUT_DIRECTORY_DEPTH")); err == nil {
+		*directoryDepth = v
+	}
+	if envSort := os.Getenv("INPUT_DIRECTORY_SORT"); envSort != "" {
+		*directorySort = envSort
+	}
+	if *maxCoverageDrop == "" {
+		*maxCoverageDrop = os.Getenv("INPUT_MAX_COVERAGE_DROP")
+	}
+	if *maxFileDrop == "" {
+		*maxFileDrop = os.Getenv("INPUT_MAX_FILE_DROP")
+	}
+	if *newFileMinimum == "" {
+		*newFileMinimum = os.Getenv("INPUT_NEW_FILE_MINIMUM")
+	}
+	if *patchTarget == 0 {
+		if v, err := strconv.ParseFloat(os.Getenv("INPUT_PATCH_TARGET"), 64); err == nil {
+			*patchTarget = v
+		}
+	}
+	if *components == "" {
+		*components = os.Getenv("INPUT_COMPONENTS")
+	}
+	if envContext := os.Getenv("INPUT_STATUS_CONTEXT"); envContext != "" {
+		*statusContext = envContext
+	}
+	if *projectStatus == "" {
+		*projectStatus = os.Getenv("INPUT_PROJECT_STATUS")
+	}
+	if *patchStatus == "" {
+		*patchStatus = os.Getenv("INPUT_PATCH_STATUS")
+	}
+	if os.Getenv("INPUT_INFORMATIONAL") == "true" {
+		*informational = true
+	}
+	if *include == "" {
+		*include = os.Getenv("INPUT_INCLUDE")
+	}
+	if *exclude == "" {
+		*exclude = os.Getenv("INPUT_EXCLUDE")
+	}
+	if os.Getenv("INPUT_DEFAULT_EXCLUDES") == "false" {
+		*defaultExcludes = false
+	}
+	if *languagesFile == "" {
+		*languagesFile = os.Getenv("INPUT_LANGUAGES")
+	}
+	if os.Getenv("INPUT_IGNORE_MARKERS") == "false" {
+		*ignoreMarkers = false
+	}
+	if os.Getenv("INPUT_CHECKS") == "true" {
+		*checks = true
+	}
+	if os.Getenv("INPUT_JOB_SUMMARY") == "false" {
+		*jobSummary = false
+	}
+	if *htmlReport == "" {
+		*htmlReport = os.Getenv("INPUT_HTML_REPORT")
+	}
+	if *commentTemplate == "" {
+		*commentTemplate = os.Getenv("INPUT_COMMENT_TEMPLATE")
+	}
+	if os.Getenv("INPUT_REVIEW_COMMENTS") == "true" {
+		*reviewComments = true
+	}
+	if v, err := strconv.Atoi(os.Getenv("INPUT_MAX_REVIEW_COMMENTS")); err == nil {
+		*maxReviewComments = v
+	}
+	if *apiURL == "" {
+		*apiURL = firstNonEmpty(os.Getenv("INPUT_API_URL"), os.Getenv("GITHUB_API_URL"))
+	}
+	if *serverURL == "" {
+		*serverURL = firstNonEmpty(os.Getenv("INPUT_SERVER_URL"), os.Getenv("GITHUB_SERVER_URL"))
+	}
+	if *caBundle == "" {
+		*caBundle = os.Getenv("INPUT_CA_BUNDLE")
+	}
+	if *appID == 0 {
+		if v, err := strconv.ParseInt(os.Getenv("INPUT_APP_ID"), 10, 64); err == nil {
+			*appID = v
+		}
+	}
+	if *appInstallationID == 0 {
+		if v, err := strconv.ParseInt(os.Getenv("INPUT_APP_INSTALLATION_ID"), 10, 64); err == nil {
+			*appInstallationID = v
+		}
+	}
+	if *appPrivateKey == "" {
+		*appPrivateKey = os.Getenv("INPUT_APP_PRIVATE_KEY")
+	}
+	if *storageBackend == "" {
+		*storageBackend = os.Getenv("INPUT_STORAGE")
+	}
+	if envBranch := os.Getenv("INPUT_STORAGE_BRANCH"); envBranch != "" {
+		*storageBranch = envBranch
+	}
+	if envDir := os.Getenv("INPUT_STORAGE_DIR"); envDir != "" {
+		*storageDir = envDir
+	}
+	if os.Getenv("INPUT_BADGES") == "true" {
+		*badges = true
+	}
+	if *coverageBuckets == "" {
+		*coverageBuckets = os.Getenv("INPUT_COVERAGE_BUCKETS")
+	}
+	if envIndicators := os.Getenv("INPUT_INDICATORS"); envIndicators != "" {
+		*indicators = envIndicators
+	}
+	if os.Getenv("INPUT_EXCLUDE_GENERATED") == "false" {
+		*excludeGenerated = false
+	}
 
 	token := os.Getenv("GITHUB_TOKEN")
 	repository := os.Getenv("GITHUB_REPOSITORY")
 	eventPath := os.Getenv("GITHUB_EVENT_PATH")
-	sha := os.Getenv("GITHUB_SHA")
 
-	if token == "" {
+	if token == "" && *appID == 0 {
 		fmt.Fprintln(os.Stderr, "GITHUB_TOKEN is required")
 		os.Exit(1)
 	}
@@ -50,11 +234,12 @@ func main() {
 	}
 	owner, repo := parts[0], parts[1]
 
-	prNumber, err := getPRNumber(eventPath)
+	event, err := github.LoadEvent(os.Getenv("GITHUB_EVENT_NAME"), eventPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to get PR number: %v\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Warning: Failed to read event payload: %v\n", err)
+		event = &github.Event{}
 	}
+	prNumber := event.PRNumber
 
 	if *coverageFile == "" {
 		*coverageFile = detectCoverageFile()
@@ -96,6 +281,23 @@ func main() {
 		os.Exit(1)
 	}
 
+	filter := paths.NewFilter(paths.SplitPatterns(*include), paths.SplitPatterns(*exclude), *defaultExcludes)
+	if *excludeGenerated {
+		filter.Generated = paths.NewGeneratedDetector(".")
+	}
+	if *languagesFile != "" {
+		registry, err := loadLanguages(*languagesFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load languages: %v\n", err)
+			os.Exit(1)
+		}
+		filter.Languages = registry
+	}
+	report.Filter(filter.Match)
+	if *ignoreMarkers {
+		report.ApplyExclusions(".")
+	}
+
 	// Parse base coverage if provided
 	var baseReport *coverage.Report
 	if *baseCoverageFile != "" {
@@ -106,6 +308,12 @@ func main() {
 				if bp, err := parser.GetParser(detected); err == nil {
 					baseReport, _ = bp.Parse(baseFile)
 					if baseReport != nil {
+						baseReport.Filter(filter.Match)
+						if *ignoreMarkers {
+							// Count the same lines as the head report; stored
+							// base reports were saved after their exclusions
+							baseReport.ApplyExclusions(".")
+						}
 						fmt.Printf("Loaded base coverage from: %s (%.2f%%)\n", *baseCoverageFile, baseReport.Coverage)
 					}
 				}
@@ -115,34 +323,212 @@ func main() {
 		}
 	}
 
-	gh := github.NewClient(token, owner, repo)
+	rules, err := coverage.ParseThresholdRules(*thresholdRules)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid threshold rules: %v\n", err)
+		os.Exit(1)
+	}
+	thresholds := coverage.Thresholds{
+		Project: *threshold,
+		File:    *fileThreshold,
+		Rules:   rules,
+	}
+	violations := thresholds.Check(report)
+
+	componentList, err := status.ParseComponents(*components)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid components: %v\n", err)
+		os.Exit(1)
+	}
+
+	var tmpl *comment.Template
+	if *commentTemplate != "" {
+		if tmpl, err = comment.LoadTemplate(*commentTemplate); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load comment template: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	switch *directorySort {
+	case coverage.SortByName, coverage.SortByCoverage, coverage.SortByChange:
+	default:
+		fmt.Fprintf(os.Stderr, "Invalid directory sort: %s\n", *directorySort)
+		os.Exit(1)
+	}
+
+	buckets, err := coverage.ParseBuckets(*coverageBuckets)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid coverage buckets: %v\n", err)
+		os.Exit(1)
+	}
+	if *indicators != "emoji" && *indicators != "text" {
+		fmt.Fprintf(os.Stderr, "Invalid indicators: %s\n", *indicators)
+		os.Exit(1)
+	}
+	textIndicators := *indicators == "text"
+
+	gates, err := ratchetGates(map[string]string{
+		coverage.GateProjectDrop: *maxCoverageDrop,
+		coverage.GateFileDrop:    *maxFileDrop,
+		coverage.GateNewFile:     *newFileMinimum,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid ratchet gate: %v\n", err)
+		os.Exit(1)
+	}
+
+	gh := github.NewClientWithBaseURL(token, owner, repo, *apiURL)
+	if *caBundle != "" {
+		httpClient, err := github.NewHTTPClientWithCABundle(*caBundle)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load CA bundle: %v\n", err)
+			os.Exit(1)
+		}
+		gh.HTTPClient = httpClient
+	}
+	if *appID != 0 {
+		keyPEM, err := loadPrivateKey(*appPrivateKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load GitHub App private key: %v\n", err)
+			os.Exit(1)
+		}
+		auth, err := github.NewAppAuth(gh, *appID, *appInstallationID, keyPEM)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid GitHub App private key: %v\n", err)
+			os.Exit(1)
+		}
+		gh.Auth = auth
+		fmt.Printf("Authenticating as GitHub App %d\n", *appID)
+	}
+
+	// Events such as issue_comment name the pull request but not its commits
+	if prNumber > 0 && event.HeadSHA == "" {
+		if pr, err := gh.GetPullRequest(prNumber); err == nil {
+			event.SetPullRequest(pr)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to get pull request: %v\n", err)
+		}
+	}
+	// GITHUB_SHA is a merge commit on pull requests; prefer the head commit
+	sha := event.HeadSHA
+	if sha == "" {
+		sha = os.Getenv("GITHUB_SHA")
+	}
+	if *baseBranch == "" {
+		*baseBranch = event.BaseRef
+	}
+	if *baseBranch == "" {
+		*baseBranch = "main"
+	}
+	if event.Fork && *appID == 0 {
+		fmt.Println("Pull request from a fork: GITHUB_TOKEN may be read-only, consider app-id")
+	}
+
+	var store storage.Storage
+	switch *storageBackend {
+	case "":
+	case "branch":
+		branch := storage.NewBranch(gh)
+		branch.Name = *storageBranch
+		store = branch
+	case "dir":
+		store = storage.NewDir(*storageDir)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown storage: %s\n", *storageBackend)
+		os.Exit(1)
+	}
+
+	if baseReport == nil && store != nil && prNumber > 0 {
+		baseReport, err = loadStoredBase(gh, store, event, sha)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: No stored base coverage: %v\n", err)
+		} else {
+			baseReport.Filter(filter.Match)
+		}
+	}
 
-	var changedFiles []string
-	if *showFiles == "changed" && prNumber > 0 {
-		changedFiles, err = gh.GetChangedFiles(prNumber)
+	// The ratchet gates and indirect changes need the files the pull request
+	// changed whatever show-files selects for display
+	var prChangedFiles, changedFiles []string
+	if prNumber > 0 {
+		prChangedFiles, err = gh.GetChangedFiles(prNumber)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: Failed to get changed files: %v\n", err)
 		}
 	}
+	if *showFiles == "changed" {
+		changedFiles = prChangedFiles
+	}
 
+	var patch *coverage.Patch
+	if prNumber > 0 {
+		diffText, err := gh.GetPullRequestDiff(prNumber)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to get pull request diff: %v\n", err)
+		} else {
+			patch = coverage.NewPatch(report, diff.ParseAddedLines(diffText))
+		}
+	}
+
+	var fileAnnotations []github.CheckAnnotation
 	if *annotations {
 		// Only filter annotations by changed files if show-files is "changed"
 		annotationFiles := changedFiles
 		if *showFiles != "changed" {
 			annotationFiles = nil // nil means show all files
 		}
-		outputAnnotations(report, annotationFiles)
+		fileAnnotations = collectAnnotations(report, annotationFiles, filter)
+		if !*checks {
+			outputAnnotations(fileAnnotations)
+		}
+	}
+	// Check runs annotate the uncovered lines the pull request adds
+	checkAnnotations := fileAnnotations
+	if *checks && patch != nil {
+		checkAnnotations = patchAnnotations(patch)
 	}
 
-	repoURL := fmt.Sprintf("https://github.com/%s", repository)
+	repoURL := comment.RepoURL(*serverURL, repository)
 	opts := comment.Options{
-		Title:        *title,
-		ShowFiles:    *showFiles,
-		ChangedFiles: changedFiles,
-		RepoURL:      repoURL,
-		SHA:          sha,
-		PRNumber:     prNumber,
-		BaseBranch:   *baseBranch,
+		Title:             *title,
+		ShowFiles:         *showFiles,
+		ChangedFiles:      changedFiles,
+		RepoURL:           repoURL,
+		SHA:               sha,
+		PRNumber:          prNumber,
+		BaseBranch:        *baseBranch,
+		Filter:            filter,
+		Violations:        violations,
+		Patch:             patch,
+		DirectoryDepth:    *directoryDepth,
+		DirectorySort:     *directorySort,
+		Thresholds:        thresholds,
+		Template:          tmpl,
+		Buckets:           buckets,
+		TextIndicators:    textIndicators,
+		IndirectThreshold: *indirectThreshold,
+	}
+	if runID := os.Getenv("GITHUB_RUN_ID"); runID != "" && *jobSummary {
+		// The job summary is shown on the workflow run page
+		opts.FullReportURL = fmt.Sprintf("%s/actions/runs/%s", repoURL, runID)
+	}
+	if h, ok := store.(storage.History); ok && prNumber > 0 {
+		entries, err := h.Recent(trendLength)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to load coverage history: %v\n", err)
+		}
+		for _, e := range entries {
+			opts.Trend = append(opts.Trend, e.Coverage)
+		}
+	}
+	var comp *coverage.Comparison
+	if baseReport != nil {
+		comp = coverage.NewComparisonWithFilter(report, baseReport, changedFiles, filter)
+		comp.FindIndirectChanges(prChangedFiles)
+		comp.AddPatch(patch)
+		opts.Gates = comp.CheckRatchet(gates, prChangedFiles, filter)
+	} else if len(gates) > 0 {
+		fmt.Println("No base coverage available, skipping ratchet gates")
 	}
 	if strings.HasPrefix(*showFiles, "threshold:") {
 		val, _ := strconv.ParseFloat(strings.TrimPrefix(*showFiles, "threshold:"), 64)
@@ -155,8 +541,7 @@ func main() {
 
 	// Generate comment with or without comparison
 	var commentBody string
-	if baseReport != nil {
-		comp := coverage.NewComparison(report, baseReport, changedFiles)
+	if comp != nil {
 		commentBody = comment.FormatWithComparison(comp, opts)
 	} else {
 		commentBody = comment.Format(report, opts)
@@ -176,27 +561,102 @@ func main() {
 			os.Exit(1)
 		}
 		fmt.Println("Coverage comment posted successfully")
+
+		if *reviewComments && patch != nil {
+			if err := postReviewComments(gh, prNumber, sha, patch, *maxReviewComments); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Failed to post review comments: %v\n", err)
+			}
+		}
 	} else {
 		fmt.Println("No PR number found, skipping comment")
 	}
 
+	statuses := status.Build(status.Config{
+		Context:        *statusContext,
+		ProjectContext: *projectStatus,
+		PatchContext:   *patchStatus,
+		ProjectTarget:  *threshold,
+		PatchTarget:    *patchTarget,
+		Components:     componentList,
+		Informational:  *informational,
+		Buckets:        buckets,
+		TextIndicators: textIndicators,
+	}, status.Input{
+		Report:     report,
+		Base:       baseReport,
+		Patch:      patch,
+		Violations: violations,
+		Gates:      opts.Gates,
+	})
+
 	if sha != "" {
-		state := "success"
-		description := fmt.Sprintf("%.2f%% coverage", report.Coverage)
-		if *threshold > 0 && report.Coverage < *threshold {
-			state = "failure"
-			description = fmt.Sprintf("%.2f%% coverage (minimum: %.2f%%)", report.Coverage, *threshold)
-		}
-		if err := gh.SetCommitStatus(sha, state, description, "litecov"); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: Failed to set commit status: %v\n", err)
+		for _, st := range statuses {
+			if err := gh.SetCommitStatus(sha, st.State, st.Description, st.Context); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Failed to set commit status %s: %v\n", st.Context, err)
+			} else {
+				fmt.Printf("Commit status set: %s %s - %s\n", st.Context, st.State, st.Description)
+			}
+		}
+	}
+
+	if store != nil && event.IsDefaultBranchPush() && sha != "" {
+		var badgeFiles map[string][]byte
+		if *badges {
+			badgeFiles = renderBadges(report, nil, componentList, badgeLabels{Project: badge.DefaultLabel}, buckets)
+		}
+		storePush(store, sha, event.BaseRef, report, badgeFiles)
+	}
+
+	if *checks && sha != "" {
+		run := github.CheckRun{
+			Name:       *statusContext,
+			HeadSHA:    sha,
+			Conclusion: github.ConclusionSuccess,
+			Title:      fmt.Sprintf("Coverage %.2f%%", report.Coverage),
+			Summary:    comment.FormatCheckRun(report, comp, opts, github.MaxCheckSummary),
+		}
+		if len(status.Failed(statuses)) > 0 {
+			run.Conclusion = github.ConclusionFailure
+		}
+		if _, err := gh.CreateCheckRun(run, checkAnnotations); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to create check run: %v\n", err)
+			outputAnnotations(checkAnnotations)
 		} else {
-			fmt.Printf("Commit status set: %s - %s\n", state, description)
+			fmt.Printf("Check run created: %s %s (%d annotations)\n", run.Name, run.Conclusion, len(checkAnnotations))
 		}
 	}
 
 	fmt.Printf("\nCoverage: %.2f%%\n", report.Coverage)
 	fmt.Printf("Lines: %d/%d\n", report.TotalCovered, report.TotalLines)
 	fmt.Printf("Files: %d\n", len(report.Files))
+	if report.ExcludedLines > 0 {
+		fmt.Printf("Excluded lines: %d\n", report.ExcludedLines)
+	}
+
+	if stepSummary := os.Getenv("GITHUB_STEP_SUMMARY"); *jobSummary && stepSummary != "" {
+		f, err := os.OpenFile(stepSummary, os.O_APPEND|os.O_WRONLY, 0644)
+		if err == nil {
+			f.WriteString(comment.FormatSummary(report, comp, opts))
+			f.Close()
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to write job summary: %v\n", err)
+		}
+	}
+
+	if *htmlReport != "" {
+		err := htmlreport.Write(*htmlReport, report, htmlreport.Options{
+			Title:   *title,
+			RepoURL: repoURL,
+			SHA:     sha,
+			Buckets: buckets,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to write HTML report: %v\n", err)
+			*htmlReport = ""
+		} else {
+			fmt.Printf("HTML report written to %s\n", *htmlReport)
+		}
+	}
 
 	if ghOutput := os.Getenv("GITHUB_OUTPUT"); ghOutput != "" {
 		f, err := os.OpenFile(ghOutput, os.O_APPEND|os.O_WRONLY, 0644)
@@ -205,40 +665,232 @@ func main() {
 			fmt.Fprintf(f, "lines-covered=%d\n", report.TotalCovered)
 			fmt.Fprintf(f, "lines-total=%d\n", report.TotalLines)
 			fmt.Fprintf(f, "files-count=%d\n", len(report.Files))
+			fmt.Fprintf(f, "lines-excluded=%d\n", report.ExcludedLines)
+			if *htmlReport != "" {
+				fmt.Fprintf(f, "html-report=%s\n", *htmlReport)
+			}
 			f.Close()
 		}
 	}
 
-	if *threshold > 0 && report.Coverage < *threshold {
-		fmt.Fprintf(os.Stderr, "\nCoverage %.2f%% is below threshold %.2f%%\n", report.Coverage, *threshold)
+	failed := status.Failed(statuses)
+	if len(failed) > 0 {
+		fmt.Fprintln(os.Stderr)
+		for _, v := range violations {
+			fmt.Fprintln(os.Stderr, violationMessage(v))
+		}
+		for _, st := range failed {
+			fmt.Fprintf(os.Stderr, "%s failed: %s\n", st.Context, st.Description)
+		}
 		os.Exit(1)
 	}
 }
 
-func getPRNumber(eventPath string) (int, error) {
-	if eventPath == "" {
-		return 0, nil
+// postReviewComments syncs litecov's review comments with the uncovered
+// added lines: new ranges are posted as one review, stale comments are
+// resolved and reopened ones updated.
+func postReviewComments(gh *github.Client, prNumber int, sha string, patch *coverage.Patch, maxComments int) error {
+	existing, err := gh.ListReviewComments(prNumber)
+	if err != nil {
+		return err
+	}
+
+	plan := review.NewPlan(review.Comments(patch), existing, maxComments)
+	for _, rc := range append(plan.Update, plan.Resolve...) {
+		if err := gh.UpdateReviewComment(rc.ID, rc.Body); err != nil {
+			return err
+		}
+	}
+	if len(plan.Create) > 0 {
+		if err := gh.CreateReview(prNumber, sha, review.ReviewBody(plan), plan.Create); err != nil {
+			return err
+		}
+	}
+	if len(plan.ResolveThreads) > 0 || len(plan.ReopenThreads) > 0 {
+		// The comments already say whether they apply, so a token that
+		// cannot resolve threads is not fatal
+		if err := updateReviewThreads(gh, prNumber, plan); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to update review threads: %v\n", err)
+		}
+	}
+
+	fmt.Printf("Review comments: %d created, %d updated, %d resolved", len(plan.Create), len(plan.Update), len(plan.Resolve))
+	if plan.Omitted > 0 {
+		fmt.Printf(", %d omitted by the cap", plan.Omitted)
+	}
+	fmt.Println()
+	return nil
+}
+
+// updateReviewThreads resolves the review threads of comments that no longer
+// apply and reopens those whose lines are uncovered again.
+func updateReviewThreads(gh *github.Client, prNumber int, plan review.Plan) error {
+	threads, err := gh.ListReviewThreads(prNumber)
+	if err != nil {
+		return err
+	}
+	resolve, reopen := review.ThreadChanges(plan, threads)
+	for _, id := range resolve {
+		if err := gh.ResolveReviewThread(id); err != nil {
+			return err
+		}
+	}
+	for _, id := range reopen {
+		if err := gh.UnresolveReviewThread(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// trendLength is how many default branch values the comment sparkline shows.
+const trendLength = 20
+
+// maxBaseAncestors is how far back from the merge base to look for a stored
+// report.
+const maxBaseAncestors = 20
+
+// loadStoredBase returns the stored report of the merge base of the pull
+// request, or of its nearest ancestor that has one. Without commit details
+// it falls back to the latest report of the base branch.
+func loadStoredBase(gh *github.Client, store storage.Storage, event *github.Event, headSHA string) (*coverage.Report, error) {
+	if event.BaseSHA != "" && headSHA != "" {
+		mergeBase, err := gh.MergeBase(event.BaseSHA, headSHA)
+		if err != nil {
+			return nil, err
+		}
+		ancestors, err := gh.ListAncestors(mergeBase, maxBaseAncestors)
+		if err != nil {
+			return nil, err
+		}
+		report, found, err := storage.FindNearest(store, ancestors)
+		if err == nil {
+			fmt.Printf("Loaded base coverage for %s (%.2f%%)\n", found, report.Coverage)
+			return report, nil
+		}
+		if !errors.Is(err, storage.ErrNotFound) {
+			return nil, err
+		}
 	}
-	data, err := os.ReadFile(eventPath)
+	if event.BaseRef == "" {
+		return nil, storage.ErrNotFound
+	}
+
+	report, found, err := store.Latest(event.BaseRef)
 	if err != nil {
-		return 0, nil
+		return nil, err
+	}
+	fmt.Printf("Loaded latest base coverage of %s at %s (%.2f%%)\n", event.BaseRef, found, report.Coverage)
+	return report, nil
+}
+
+// storePush saves the report of a default branch push along with its history
+// entry and badges. On the data branch they are added in a single commit.
+func storePush(store storage.Storage, sha, branch string, report *coverage.Report, badgeFiles map[string][]byte) {
+	dataBranch, batched := store.(*storage.Branch)
+	if batched {
+		dataBranch.Batch()
+	}
+
+	var stored []string
+	if err := store.Save(sha, branch, report); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Failed to store coverage report: %v\n", err)
+	} else {
+		stored = append(stored, "coverage report")
+	}
+	if h, ok := store.(storage.History); ok {
+		if err := h.Append(storage.NewHistoryEntry(sha, time.Now(), report)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to record coverage history: %v\n", err)
+		} else {
+			stored = append(stored, "history entry")
+		}
+	}
+	if badgeFiles != nil {
+		if err := storeBadges(store, badgeFiles); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to store badges: %v\n", err)
+		} else {
+			stored = append(stored, fmt.Sprintf("%d coverage badges", len(badgeFiles)))
+		}
 	}
 
-	content := string(data)
-	if idx := strings.Index(content, `"number":`); idx >= 0 {
-		start := idx + 9
-		for start < len(content) && (content[start] == ' ' || content[start] == '\t') {
-			start++
+	if batched {
+		if err := dataBranch.Flush(fmt.Sprintf("Coverage data for %s on %s", sha, branch)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to commit coverage data to %s: %v\n", dataBranch.Name, err)
+			return
 		}
-		end := start
-		for end < len(content) && content[end] >= '0' && content[end] <= '9' {
-			end++
+	}
+	if len(stored) > 0 {
+		fmt.Printf("Stored %s for %s\n", strings.Join(stored, ", "), sha)
+	}
+}
+
+// ratchetGates builds the ratchet gates whose limits are set. Limits are
+// keyed by gate kind; empty values disable the gate.
+func ratchetGates(limits map[string]string) ([]coverage.RatchetGate, error) {
+	var gates []coverage.RatchetGate
+	for _, kind := range []string{coverage.GateProjectDrop, coverage.GateFileDrop, coverage.GateNewFile} {
+		value := strings.TrimSuffix(strings.TrimSpace(limits[kind]), "%")
+		if value == "" {
+			continue
 		}
-		if end > start {
-			return strconv.Atoi(content[start:end])
+		limit, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", kind, err)
+		}
+		gates = append(gates, coverage.RatchetGate{Kind: kind, Limit: limit})
+	}
+	return gates, nil
+}
+
+// violationMessage describes a threshold violation for the job log.
+func violationMessage(v coverage.Violation) string {
+	switch v.Rule {
+	case "project":
+		return fmt.Sprintf("Coverage %.2f%% is below threshold %.2f%%", v.Coverage, v.Minimum)
+	case "file":
+		return fmt.Sprintf("Files below %.2f%% coverage: %s", v.Minimum, strings.Join(v.Files, ", "))
+	default:
+		return fmt.Sprintf("Coverage of %s is %.2f%%, below threshold %.2f%%", v.Rule, v.Coverage, v.Minimum)
+	}
+}
+
+// loadPrivateKey returns a PEM private key given inline or as a file path.
+func loadPrivateKey(value string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("app-private-key is required with app-id")
+	}
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return os.ReadFile(value)
+}
+
+// firstNonEmpty returns the first non-empty value.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
 		}
 	}
-	return 0, nil
+	return ""
+}
+
+// loadLanguages returns the default language registry extended with the
+// definitions from a JSON file.
+func loadLanguages(path string) (*paths.Registry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	languages, err := paths.LoadLanguages(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	registry := paths.DefaultRegistry()
+	registry.Register(languages...)
+	return registry, nil
 }
 
 func detectCoverageFile() string {
@@ -259,7 +911,12 @@ func detectCoverageFile() string {
 	return ""
 }
 
-func outputAnnotations(report *coverage.Report, changedFiles []string) {
+// collectAnnotations returns annotations for the uncovered lines of the
+// report's files and for changed source files without coverage data. When
+// changedFiles is empty every file in the report is annotated.
+func collectAnnotations(report *coverage.Report, changedFiles []string, filter *paths.Filter) []github.CheckAnnotation {
+	var annotations []github.CheckAnnotation
+
 	changedSet := make(map[string]bool)
 	for _, f := range changedFiles {
 		changedSet[f] = true
@@ -295,27 +952,75 @@ func outputAnnotations(report *coverage.Report, changedFiles []string) {
 
 		ranges := comment.GroupConsecutiveLines(file.UncoveredLines)
 		for _, r := range ranges {
-			if r.Start == r.End {
-				fmt.Printf("::warning file=%s,line=%d,title=Uncovered::Line %d not covered by tests\n",
-					annotationPath, r.Start, r.Start)
-			} else {
-				fmt.Printf("::warning file=%s,line=%d,endLine=%d,title=Uncovered::Lines %d-%d not covered by tests\n",
-					annotationPath, r.Start, r.End, r.Start, r.End)
+			message := fmt.Sprintf("Line %d not covered by tests", r.Start)
+			if r.Start != r.End {
+				message = fmt.Sprintf("Lines %d-%d not covered by tests", r.Start, r.End)
 			}
+			annotations = append(annotations, github.CheckAnnotation{
+				Path:            annotationPath,
+				StartLine:       r.Start,
+				EndLine:         r.End,
+				AnnotationLevel: github.AnnotationWarning,
+				Title:           "Uncovered",
+				Message:         message,
+			})
 		}
 	}
 
-	// Output annotations for changed files that have no coverage data at all
+	// Annotate changed files that have no coverage data at all
 	// These are files that were never executed by any test
 	for _, changedFile := range changedFiles {
 		if coveredChangedFiles[changedFile] {
 			continue
 		}
 		// Only annotate source files (skip test files, configs, etc.)
-		if !paths.IsSourceFile(changedFile) {
+		if !filter.IsSourceFile(changedFile) {
 			continue
 		}
-		fmt.Printf("::warning file=%s,line=1,title=No Coverage::File has no test coverage\n", changedFile)
+		annotations = append(annotations, github.CheckAnnotation{
+			Path:            changedFile,
+			StartLine:       1,
+			EndLine:         1,
+			AnnotationLevel: github.AnnotationWarning,
+			Title:           "No Coverage",
+			Message:         "File has no test coverage",
+		})
 	}
+
+	return annotations
 }
 
+// patchAnnotations returns annotations for the uncovered lines added by the
+// pull request.
+func patchAnnotations(patch *coverage.Patch) []github.CheckAnnotation {
+	var annotations []github.CheckAnnotation
+	for _, pf := range patch.Files {
+		for _, r := range comment.GroupConsecutiveLines(pf.UncoveredLines) {
+			message := fmt.Sprintf("Added line %d not covered by tests", r.Start)
+			if r.Start != r.End {
+				message = fmt.Sprintf("Added lines %d-%d not covered by tests", r.Start, r.End)
+			}
+			annotations = append(annotations, github.CheckAnnotation{
+				Path:            pf.Path,
+				StartLine:       r.Start,
+				EndLine:         r.End,
+				AnnotationLevel: github.AnnotationWarning,
+				Title:           "Uncovered",
+				Message:         message,
+			})
+		}
+	}
+	return annotations
+}
+
+// outputAnnotations prints annotations as workflow commands.
+func outputAnnotations(annotations []github.CheckAnnotation) {
+	for _, a := range annotations {
+		if a.EndLine > a.StartLine {
+			fmt.Printf("::warning file=%s,line=%d,endLine=%d,title=%s::%s\n",
+				a.Path, a.StartLine, a.EndLine, a.Title, a.Message)
+		} else {
+			fmt.Printf("::warning file=%s,line=%d,title=%s::%s\n", a.Path, a.StartLine, a.Title, a.Message)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Package badge renders shields-style SVG coverage badges without an
+// external service.
+package badge
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"math"
+
+	"github.com/manashmandal/litecov/internal/coverage"
+)
+
+// DefaultLabel is the text on the left of a project coverage badge.
+const DefaultLabel = "coverage"
+
+// noColor fills the message of a badge without a colour.
+const noColor = "#9f9f9f"
+
+// Badge is a two part badge: a grey label and a coloured message.
+type Badge struct {
+	Label   string
+	Message string
+	Color   string
+}
+
+// Coverage returns a badge showing pct in the colour of its bucket.
+func Coverage(label string, pct float64, buckets coverage.Buckets) Badge {
+	return Badge{
+		Label:   label,
+		Message: fmt.Sprintf("%.1f%%", pct),
+		Color:   buckets.Find(pct).Color,
+	}
+}
+
+// horizontalPadding is the space on each side of a segment's text.
+const horizontalPadding = 5
+
+// SVG renders the badge in the shields.io "flat" style. Text is drawn at ten
+// times the size and scaled down, as shields does, for crisper rendering.
+func (b Badge) SVG() []byte {
+	labelWidth := segmentWidth(b.Label)
+	messageWidth := segmentWidth(b.Message)
+	width := labelWidth + messageWidth
+	label := html.EscapeString(b.Label)
+	message := html.EscapeString(b.Message)
+	color := b.Color
+	if color == "" {
+		color = noColor
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="20" role="img" aria-label="%s: %s">`, width, label, message)
+	fmt.Fprintf(&buf, `<title>%s: %s</title>`, label, message)
+	buf.WriteString(`<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`)
+	fmt.Fprintf(&buf, `<clipPath id="r"><rect width="%d" height="20" rx="3" fill="#fff"/></clipPath>`, width)
+	fmt.Fprintf(&buf, `<g clip-path="url(#r)"><rect width="%d" height="20" fill="#555"/><rect x="%d" width="%d" height="20" fill="%s"/><rect width="%d" height="20" fill="url(#s)"/></g>`,
+		labelWidth, labelWidth, messageWidth, html.EscapeString(color), width)
+	buf.WriteString(`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" text-rendering="geometricPrecision" font-size="110">`)
+	writeText(&buf, label, b.Label, 0, labelWidth)
+	writeText(&buf, message, b.Message, labelWidth, messageWidth)
+	buf.WriteString(`</g></svg>`)
+	buf.WriteString("\n")
+	return buf.Bytes()
+}
+
+// writeText draws escaped text centred in the segment starting at x, with a
+// drop shadow below it.
+func writeText(buf *bytes.Buffer, escaped, raw string, x, width int) {
+	center := x*10 + width*5
+	length := int(math.Round(TextWidth(raw) * 10))
+	fmt.Fprintf(buf, `<text aria-hidden="true" x="%d" y="150" fill="#010101" fill-opacity=".3" transform="scale(.1)" textLength="%d">%s</text>`, center, length, escaped)
+	fmt.Fprintf(buf, `<text x="%d" y="140" transform="scale(.1)" fill="#fff" textLength="%d">%s</text>`, center, length, escaped)
+}
+
+func segmentWidth(text string) int {
+	return int(math.Ceil(TextWidth(text))) + 2*horizontalPadding
+}
@@ -0,0 +1,95 @@
+package badge
+
+import (
+	"encoding/xml"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/manashmandal/litecov/internal/coverage"
+)
+
+func TestTextWidth(t *testing.T) {
+	if len(verdanaWidths) != '~'-' '+1 {
+		t.Fatalf("width table has %d entries, want all printable ASCII", len(verdanaWidths))
+	}
+
+	tests := []struct {
+		text string
+		want float64
+	}{
+		{"", 0},
+		{"coverage", 5.73 + 6.68 + 6.51 + 6.55*2 + 4.69 + 6.61 + 6.85},
+		{"85.0%", 7.00*3 + 4.00 + 11.84},
+		{"iii", 3.02 * 3},
+		{"é", fallbackWidth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := TextWidth(tt.text); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("TextWidth(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+
+	if TextWidth("WWW") <= TextWidth("iii") {
+		t.Error("wide characters should measure wider than narrow ones")
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	buckets := coverage.Buckets{{Min: 90, Color: "#4c1"}, {Min: 70, Color: "#dfb317"}, {Min: 0, Color: "#e05d44"}}
+	tests := []struct {
+		pct  float64
+		want string
+	}{
+		{95, "#4c1"},
+		{85, "#dfb317"},
+		{60, "#e05d44"},
+	}
+
+	for _, tt := range tests {
+		if got := Coverage("coverage", tt.pct, buckets).Color; got != tt.want {
+			t.Errorf("Coverage(%v).Color = %s, want %s", tt.pct, got, tt.want)
+		}
+	}
+}
+
+func TestBadge_SVG(t *testing.T) {
+	b := Coverage("coverage", 85.04, coverage.DefaultBuckets)
+	if b.Message != "85.0%" || b.Color != "#4c1" {
+		t.Fatalf("Coverage() = %+v", b)
+	}
+
+	svg := string(b.SVG())
+	if err := xml.Unmarshal([]byte(svg), new(struct{})); err != nil {
+		t.Fatalf("SVG is not well formed: %v\n%s", err, svg)
+	}
+
+	// label 51 + 10 padding, message 37 + 10 padding
+	for _, want := range []string{
+		`width="108"`,
+		`<rect width="61" height="20" fill="#555"/>`,
+		`<rect x="61" width="47" height="20" fill="#4c1"/>`,
+		`<title>coverage: 85.0%</title>`,
+		`textLength="502">coverage</text>`,
+	} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG missing %q:\n%s", want, svg)
+		}
+	}
+}
+
+func TestBadge_SVGEscapes(t *testing.T) {
+	svg := string(Badge{Label: "a<b>&c", Message: "1%"}.SVG())
+	if strings.Contains(svg, "a<b>") {
+		t.Error("label should be escaped")
+	}
+	if !strings.Contains(svg, "a&lt;b&gt;&amp;c") {
+		t.Errorf("escaped label missing:\n%s", svg)
+	}
+	if !strings.Contains(svg, `fill="#9f9f9f"`) {
+		t.Error("badge without colour should be light grey")
+	}
+}
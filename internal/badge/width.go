@@ -0,0 +1,38 @@
+package badge
+
+import "unicode/utf8"
+
+// verdanaWidths holds the advance width in pixels of printable ASCII
+// characters, starting at space, in 11px Verdana as used by shields.io.
+var verdanaWidths = [...]float64{
+	3.87, 4.33, 5.05, 9.00, 7.00, 11.84, 7.99, 2.95, // space ! " # $ % & '
+	4.99, 4.99, 7.00, 9.00, 4.00, 4.99, 4.00, 4.99, // ( ) * + , - . /
+	7.00, 7.00, 7.00, 7.00, 7.00, 7.00, 7.00, 7.00, // 0-7
+	7.00, 7.00, 4.99, 4.99, 9.00, 9.00, 9.00, 6.00, // 8 9 : ; < = > ?
+	11.00, 7.52, 7.54, 7.68, 8.48, 6.96, 6.32, 8.53, // @ A-G
+	8.27, 4.63, 5.00, 7.62, 6.12, 9.27, 8.23, 8.66, // H-O
+	6.63, 8.66, 7.65, 7.52, 6.78, 8.05, 7.52, 10.88, // P-W
+	7.54, 6.77, 7.54, 4.99, 4.99, 4.99, 9.00, 7.00, // X Y Z [ \ ] ^ _
+	7.00, 6.61, 6.85, 5.73, 6.85, 6.55, 3.87, 6.85, // ` a-g
+	6.96, 3.02, 3.79, 6.51, 3.02, 10.66, 6.96, 6.68, // h-o
+	6.85, 6.85, 4.69, 5.73, 4.33, 6.96, 6.51, 9.00, // p-w
+	6.51, 6.51, 5.78, 6.98, 4.99, 6.98, 9.00, // x y z { | } ~
+}
+
+// fallbackWidth is used for characters outside printable ASCII. It matches
+// the widest lowercase letter so that labels are never clipped.
+const fallbackWidth = 10.66
+
+// TextWidth returns the rendered width of s in pixels at 11px Verdana.
+// Badges need it to size their segments since SVG has no layout engine.
+func TextWidth(s string) float64 {
+	var width float64
+	for _, r := range s {
+		if r >= ' ' && int(r-' ') < len(verdanaWidths) {
+			width += verdanaWidths[r-' ']
+		} else if r != utf8.RuneError {
+			width += fallbackWidth
+		}
+	}
+	return width
+}
@@ -21,41 +21,45 @@ type Options struct {
 	SHA          string
 	PRNumber     int
 	BaseBranch   string
+	Filter       *paths.Filter
+	Violations   []coverage.Violation
+	Gates        []coverage.GateResult
+	Patch        *coverage.Patch
+	// Trend holds recent default branch coverage values, oldest first.
+	Trend []float64
+	// DirectoryDepth enables a table of directory rollups this many levels
+	// deep. DirectorySort orders it by name, coverage or change.
+	DirectoryDepth int
+	DirectorySort  string
+	// Thresholds are the configured coverage minimums, for templates.
+	Thresholds coverage.Thresholds
+	// Template renders the comment, or nil for the DefaultTemplate.
+	Template *Template
+	// FullReportURL links to the complete report, such as the job summary,
+	// when the comment has to be truncated.
+	FullReportURL string
+	// Buckets set the coverage ranges marked in the status columns, nil
+	// meaning coverage.DefaultBuckets. TextIndicators marks them with the
+	// bucket labels instead of emoji.
+	Buckets        coverage.Buckets
+	TextIndicators bool
+	// IndirectThreshold is the change in coverage, in percentage points, an
+	// unchanged file must exceed to be listed as an indirect change.
+	IndirectThreshold float64
+
+	// compactLines replaces uncovered line links with counts, and
+	// omittedFiles counts table rows dropped, to fit the size limit.
+	compactLines    bool
+	omittedFiles    int
+	omittedIndirect int
 }
 
 func Format(report *coverage.Report, opts Options) string {
-	var sb strings.Builder
-
-	sb.WriteString(Marker)
-	sb.WriteString("\n")
-
-	sb.WriteString(formatHeader(opts))
-	sb.WriteString(formatQuickSummary(report))
-	sb.WriteString(formatCoverageDiff(report))
-
-	filesToShow := filterFiles(report.Files, opts)
-
-	// Add files with no coverage when showing changed files
-	if opts.ShowFiles == "changed" && len(opts.ChangedFiles) > 0 {
-		missingFiles := findMissingFiles(report, opts.ChangedFiles)
-		for _, path := range missingFiles {
-			filesToShow = append(filesToShow, coverage.FileCoverage{
-				Path:         path,
-				LinesCovered: 0,
-				LinesTotal:   0,
-			})
-		}
-	}
-
-	sb.WriteString(formatImpactedFiles(filesToShow, opts))
-
-	sb.WriteString(formatFooter())
-
-	return sb.String()
+	return render(newTemplateData(report, nil, opts), MaxCommentSize)
 }
 
 // findMissingFiles returns changed source files that are not in the coverage report
-func findMissingFiles(report *coverage.Report, changedFiles []string) []string {
+func findMissingFiles(report *coverage.Report, changedFiles []string, filter *paths.Filter) []string {
 	// Build map of covered files
 	coveredPaths := make(map[string]bool)
 	for _, f := range report.Files {
@@ -65,7 +69,7 @@ func findMissingFiles(report *coverage.Report, changedFiles []string) []string {
 
 	var missing []string
 	for _, changedFile := range changedFiles {
-		if !paths.IsSourceFile(changedFile) {
+		if !filter.IsSourceFile(changedFile) {
 			continue
 		}
 		// Check if file is in coverage report (direct or suffix match)
@@ -87,24 +91,21 @@ func findMissingFiles(report *coverage.Report, changedFiles []string) []string {
 	return missing
 }
 
-
 func FormatWithComparison(comp *coverage.Comparison, opts Options) string {
 	if comp == nil || comp.Head == nil {
 		return ""
 	}
+	return render(newTemplateData(comp.Head, comp, opts), MaxCommentSize)
+}
 
-	var sb strings.Builder
-
-	sb.WriteString(Marker)
-	sb.WriteString("\n")
-
-	sb.WriteString(formatHeader(opts))
-	sb.WriteString(formatQuickSummaryWithDelta(comp))
-	sb.WriteString(formatCoverageDiffWithComparison(comp, opts))
-	sb.WriteString(formatImpactedFilesWithDelta(comp.FileChanges, opts))
-	sb.WriteString(formatFooter())
-
-	return sb.String()
+// FormatCheckRun renders the comment without the Marker, shortened to limit
+// characters, for the summary of a check run. comp may be nil when there is
+// no base report.
+func FormatCheckRun(report *coverage.Report, comp *coverage.Comparison, opts Options, limit int) string {
+	if comp != nil && comp.Head != nil {
+		return renderPrefixed(newTemplateData(comp.Head, comp, opts), "", limit)
+	}
+	return renderPrefixed(newTemplateData(report, nil, opts), "", limit)
 }
 
 func formatHeader(opts Options) string {
@@ -116,17 +117,34 @@ func formatHeader(opts Options) string {
 	return fmt.Sprintf("## %s %s\n\n", logo, title)
 }
 
-func formatQuickSummary(report *coverage.Report) string {
-	emoji := getStatusEmoji(report.Coverage)
-	return fmt.Sprintf("> %s **Coverage:** `%.2f%%` | **Lines:** `%d/%d` | **Files:** `%d`\n\n",
-		emoji, report.Coverage, report.TotalCovered, report.TotalLines, len(report.Files))
+func formatQuickSummary(report *coverage.Report, opts Options) string {
+	emoji := opts.indicator(report.Coverage)
+	return fmt.Sprintf("> %s **Coverage:** `%.2f%%`%s%s | **Lines:** `%d/%d` | **Files:** `%d`%s\n\n",
+		emoji, report.Coverage, formatTrend(opts.Trend), formatPatch(opts.Patch), report.TotalCovered, report.TotalLines, len(report.Files), formatExcluded(report))
 }
 
-func formatQuickSummaryWithDelta(comp *coverage.Comparison) string {
-	emoji := getStatusEmoji(comp.Head.Coverage)
+func formatQuickSummaryWithDelta(comp *coverage.Comparison, opts Options) string {
+	emoji := opts.indicator(comp.Head.Coverage)
 	delta := formatDeltaString(comp.CoverageDelta, comp.Base != nil)
-	return fmt.Sprintf("> %s **Coverage:** `%.2f%%`%s | **Lines:** `%d/%d` | **Files:** `%d`\n\n",
-		emoji, comp.Head.Coverage, delta, comp.Head.TotalCovered, comp.Head.TotalLines, len(comp.Head.Files))
+	return fmt.Sprintf("> %s **Coverage:** `%.2f%%`%s%s%s | **Lines:** `%d/%d` | **Files:** `%d`%s\n\n",
+		emoji, comp.Head.Coverage, formatTrend(opts.Trend), delta, formatPatch(opts.Patch), comp.Head.TotalCovered, comp.Head.TotalLines, len(comp.Head.Files), formatExcluded(comp.Head))
+}
+
+// formatPatch shows the coverage of lines added in the pull request.
+func formatPatch(patch *coverage.Patch) string {
+	if patch == nil || patch.Total == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" | **Patch:** `%.2f%%`", patch.Coverage())
+}
+
+// formatExcluded reports lines dropped by litecov:ignore markers so that
+// exclusions stay visible to reviewers.
+func formatExcluded(report *coverage.Report) string {
+	if report.ExcludedLines == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" | **Excluded:** `%d`", report.ExcludedLines)
 }
 
 func formatDeltaString(delta float64, hasBase bool) string {
@@ -142,6 +160,97 @@ func formatDeltaString(delta float64, hasBase bool) string {
 	return fmt.Sprintf(" (%.2f%%)", delta)
 }
 
+// formatViolations lists the coverage thresholds that were not met.
+func formatViolations(violations []coverage.Violation, opts Options) string {
+	if len(violations) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	if opts.TextIndicators {
+		sb.WriteString("### Coverage thresholds not met\n\n")
+	} else {
+		sb.WriteString("### \u274C Coverage thresholds not met\n\n")
+	}
+	sb.WriteString("| Rule | Coverage | Minimum | Files |\n")
+	sb.WriteString("|------|----------|---------|-------|\n")
+
+	for _, v := range violations {
+		rule := fmt.Sprintf("`%s`", v.Rule)
+		switch v.Rule {
+		case "project":
+			rule = "Project"
+		case "file":
+			rule = "Any file"
+		}
+
+		sb.WriteString(fmt.Sprintf("| %s | `%.2f%%` | `%.2f%%` | %s |\n", rule, v.Coverage, v.Minimum, formatFileList(v.Files, opts)))
+	}
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+// formatGates shows the pass/fail result of each ratchet gate.
+func formatGates(gates []coverage.GateResult, opts Options) string {
+	if len(gates) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("<details>\n")
+	sb.WriteString("<summary>Coverage Gates</summary>\n\n")
+	sb.WriteString("| Gate | Result | Files | Status |\n")
+	sb.WriteString("|------|--------|-------|--------|\n")
+
+	for _, g := range gates {
+		name := g.Gate.Kind
+		switch g.Gate.Kind {
+		case coverage.GateProjectDrop:
+			name = "Project drop"
+		case coverage.GateFileDrop:
+			name = "File drop"
+		case coverage.GateNewFile:
+			name = "New files"
+		}
+		status := "\u2705"
+		switch {
+		case opts.TextIndicators && g.Passed:
+			status = "pass"
+		case opts.TextIndicators:
+			status = "fail"
+		case !g.Passed:
+			status = "\u274C"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", name, g.Description(), formatFileList(g.Files, opts), status))
+	}
+
+	sb.WriteString("\n</details>\n\n")
+
+	return sb.String()
+}
+
+// formatFileList renders up to five linked file names.
+func formatFileList(files []string, opts Options) string {
+	if len(files) == 0 {
+		return "-"
+	}
+	var names []string
+	for i, f := range files {
+		if i == 5 {
+			break
+		}
+		names = append(names, formatFileName(f, opts))
+	}
+	list := strings.Join(names, ", ")
+	if len(files) > 5 {
+		list += fmt.Sprintf(" +%d more", len(files)-5)
+	}
+	return list
+}
+
 func formatCoverageDiff(report *coverage.Report) string {
 	var sb strings.Builder
 
@@ -243,30 +352,110 @@ func formatCoverageDiffWithComparison(comp *coverage.Comparison, opts Options) s
 }
 
 func formatImpactedFiles(files []coverage.FileCoverage, opts Options) string {
-	if len(files) == 0 {
+	if len(files) == 0 && opts.omittedFiles == 0 {
 		return ""
 	}
 
 	var sb strings.Builder
 
 	sb.WriteString("<details>\n")
-	sb.WriteString(fmt.Sprintf("<summary>Impacted Files (%d)</summary>\n\n", len(files)))
+	sb.WriteString(fmt.Sprintf("<summary>Impacted Files (%d)</summary>\n\n", len(files)+opts.omittedFiles))
 	sb.WriteString("| File | Coverage | Uncovered Lines | Status |\n")
 	sb.WriteString("|------|----------|-----------------|--------|\n")
 
 	for _, f := range files {
-		pct := f.Percentage()
-		emoji := getStatusEmoji(pct)
-		fileName := formatFileName(f.Path, opts)
-		coverageStr := fmt.Sprintf("`%.2f%%`", pct)
-		uncoveredStr := formatUncoveredLines(f.UncoveredLines, opts.RepoURL, opts.SHA, f.Path)
-		// Mark files with no coverage data
-		if f.LinesTotal == 0 {
-			coverageStr = "`⚠️ no tests`"
-			uncoveredStr = "-"
-			emoji = "❌"
+		sb.WriteString(formatFileRow(f, opts))
+	}
+	sb.WriteString(formatOmittedFiles(opts.omittedFiles, 4))
+
+	sb.WriteString("\n</details>\n\n")
+
+	return sb.String()
+}
+
+// formatFileRow renders a file as a row of the impacted files table.
+func formatFileRow(f coverage.FileCoverage, opts Options) string {
+	pct := f.Percentage()
+	emoji := opts.indicator(pct)
+	fileName := formatFileName(f.Path, opts)
+	coverageStr := fmt.Sprintf("`%.2f%%`", pct)
+	uncoveredStr := formatUncoveredLines(f.UncoveredLines, opts.RepoURL, opts.SHA, f.Path)
+	if opts.compactLines && len(f.UncoveredLines) > 0 {
+		uncoveredStr = fmt.Sprintf("%d lines", len(f.UncoveredLines))
+	}
+	// Mark files with no coverage data
+	if f.LinesTotal == 0 {
+		coverageStr = formatNoTests(opts)
+		uncoveredStr = "-"
+		emoji = opts.lowestIndicator()
+	}
+	return fmt.Sprintf("| %s | %s | %s | %s |\n", fileName, coverageStr, uncoveredStr, emoji)
+}
+
+// comparisonFiles returns the head files of the comparison's file changes
+// and their base versions, for rolling up by directory.
+func comparisonFiles(comp *coverage.Comparison) (head, base []coverage.FileCoverage) {
+	changed := make(map[string]bool, len(comp.FileChanges))
+	for _, fc := range comp.FileChanges {
+		changed[fc.Path] = true
+	}
+
+	selected := make(map[string]bool)
+	for _, f := range comp.Head.Files {
+		if match := paths.FindMatchingChangedFile(f.Path, changed); match != "" {
+			head = append(head, f)
+			selected[f.Path] = true
+			delete(changed, match)
+		}
+	}
+	// Changed files without coverage data still belong to a directory
+	for _, fc := range comp.FileChanges {
+		if changed[fc.Path] {
+			head = append(head, coverage.FileCoverage{Path: fc.Path})
 		}
-		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", fileName, coverageStr, uncoveredStr, emoji))
+	}
+
+	if comp.Base != nil {
+		for _, f := range comp.Base.Files {
+			if selected[f.Path] {
+				base = append(base, f)
+			}
+		}
+	}
+	return head, base
+}
+
+// formatDirectories renders the coverage of each directory rolled up to
+// opts.DirectoryDepth, with the change from base when withDelta is set.
+func formatDirectories(root *coverage.Dir, withDelta bool, opts Options) string {
+	if opts.DirectoryDepth <= 0 || len(root.Files) == 0 && len(root.Dirs) == 0 {
+		return ""
+	}
+	dirs := root.Rollup(opts.DirectoryDepth)
+	coverage.SortDirs(dirs, opts.DirectorySort)
+
+	var sb strings.Builder
+
+	sb.WriteString("<details>\n")
+	sb.WriteString(fmt.Sprintf("<summary>Directories (%d)</summary>\n\n", len(dirs)))
+	if withDelta {
+		sb.WriteString("| Directory | Coverage | \u0394 | Lines | Status |\n")
+		sb.WriteString("|-----------|----------|---|-------|--------|\n")
+	} else {
+		sb.WriteString("| Directory | Coverage | Lines | Status |\n")
+		sb.WriteString("|-----------|----------|-------|--------|\n")
+	}
+
+	for _, d := range dirs {
+		name := d.Path + "/"
+		if d.Path == "" {
+			name = "./"
+		}
+		sb.WriteString(fmt.Sprintf("| `%s` | `%.2f%%` | ", name, d.Coverage()))
+		if withDelta {
+			sb.WriteString(formatDirDelta(d) + " | ")
+		}
+		sb.WriteString(fmt.Sprintf("%d/%d | %s |\n", d.Covered, d.Total, opts.indicator(d.Coverage())))
 	}
 
 	sb.WriteString("\n</details>\n\n")
@@ -274,33 +463,110 @@ func formatImpactedFiles(files []coverage.FileCoverage, opts Options) string {
 	return sb.String()
 }
 
+func formatDirDelta(d *coverage.Dir) string {
+	delta, ok := d.Delta()
+	switch {
+	case !ok:
+		return "`new`"
+	case delta == 0:
+		return "`ø`"
+	case delta > 0:
+		return fmt.Sprintf("`+%.2f%%`", delta)
+	default:
+		return fmt.Sprintf("`%.2f%%`", delta)
+	}
+}
+
+// formatImpactedFilesWithDelta renders the file changes with their change
+// from base and uncovered lines. With a patch the lines column lists only the
+// uncovered lines added by the pull request.
 func formatImpactedFilesWithDelta(fileChanges []coverage.FileChange, opts Options) string {
-	if len(fileChanges) == 0 {
+	if len(fileChanges) == 0 && opts.omittedFiles == 0 {
 		return ""
 	}
 
 	var sb strings.Builder
 
+	linesHeader := "Uncovered Lines"
+	if opts.Patch != nil {
+		linesHeader = "Uncovered Added Lines"
+	}
+
 	sb.WriteString("<details>\n")
-	sb.WriteString(fmt.Sprintf("<summary>Impacted Files (%d)</summary>\n\n", len(fileChanges)))
-	sb.WriteString("| File | Coverage | \u0394 | Status |\n")
-	sb.WriteString("|------|----------|---|--------|\n")
+	sb.WriteString(fmt.Sprintf("<summary>Impacted Files (%d)</summary>\n\n", len(fileChanges)+opts.omittedFiles))
+	sb.WriteString(fmt.Sprintf("| File | Coverage | \u0394 | New Uncovered | %s | Status |\n", linesHeader))
+	sb.WriteString("|------|----------|---|---------------|" + strings.Repeat("-", len(linesHeader)+2) + "|--------|\n")
 
 	for _, fc := range fileChanges {
-		emoji := getStatusEmoji(fc.HeadCoverage)
+		emoji := opts.indicator(fc.HeadCoverage)
 		fileName := formatFileName(fc.Path, opts)
-		deltaStr := formatFileDelta(fc)
-		sb.WriteString(fmt.Sprintf("| %s | `%.2f%%` | %s | %s |\n", fileName, fc.HeadCoverage, deltaStr, emoji))
+		deltaStr := formatFileDelta(fc, opts)
+		sb.WriteString(fmt.Sprintf("| %s | `%.2f%%` | %s | %s | %s | %s |\n",
+			fileName, fc.HeadCoverage, deltaStr, formatNewlyUncovered(fc), formatChangeLines(fc, opts), emoji))
+	}
+	sb.WriteString(formatOmittedFiles(opts.omittedFiles, 6))
+
+	sb.WriteString("\n</details>\n\n")
+
+	return sb.String()
+}
+
+// formatIndirectChanges lists files the pull request did not touch whose
+// coverage changed anyway, such as after tests were removed.
+func formatIndirectChanges(changes []coverage.FileChange, opts Options) string {
+	if len(changes) == 0 && opts.omittedIndirect == 0 {
+		return ""
 	}
 
+	var sb strings.Builder
+
+	sb.WriteString("<details>\n")
+	sb.WriteString(fmt.Sprintf("<summary>Indirect Changes (%d)</summary>\n\n", len(changes)+opts.omittedIndirect))
+	sb.WriteString("Files not changed in this pull request whose coverage changed.\n\n")
+	sb.WriteString("| File | Coverage | \u0394 | New Uncovered | Status |\n")
+	sb.WriteString("|------|----------|---|---------------|--------|\n")
+
+	for _, fc := range changes {
+		sb.WriteString(fmt.Sprintf("| %s | `%.2f%%` | %s | %s | %s |\n",
+			formatFileName(fc.Path, opts), fc.HeadCoverage, formatFileDelta(fc, opts), formatNewlyUncovered(fc), opts.indicator(fc.HeadCoverage)))
+	}
+	sb.WriteString(formatOmittedFiles(opts.omittedIndirect, 5))
+
 	sb.WriteString("\n</details>\n\n")
 
 	return sb.String()
 }
 
-func formatFileDelta(fc coverage.FileChange) string {
+func formatNewlyUncovered(fc coverage.FileChange) string {
+	switch {
+	case fc.NoCoverage:
+		return "-"
+	case fc.NewlyUncovered == 0:
+		return "`ø`"
+	default:
+		return fmt.Sprintf("`%+d`", fc.NewlyUncovered)
+	}
+}
+
+// formatChangeLines links the uncovered added lines of fc when opts has a
+// patch, and all its uncovered lines otherwise.
+func formatChangeLines(fc coverage.FileChange, opts Options) string {
 	if fc.NoCoverage {
-		return "`⚠️ no tests`"
+		return "-"
+	}
+	lines := fc.UncoveredLines
+	if opts.Patch != nil {
+		lines = fc.UncoveredAdded
+	}
+	if opts.compactLines && len(lines) > 0 {
+		return fmt.Sprintf("%d lines", len(lines))
+	}
+	return formatUncoveredLines(lines, opts.RepoURL, opts.SHA, fc.Path)
+}
+
+func formatFileDelta(fc coverage.FileChange, opts Options) string {
+	if fc.NoCoverage {
+		return formatNoTests(opts)
 	}
 	if fc.IsNew {
 		return "`new`"
@@ -316,24 +582,48 @@ func formatFileDelta(fc coverage.FileChange) string {
 
 func formatFileName(path string, opts Options) string {
 	if opts.RepoURL != "" && opts.SHA != "" {
-		return fmt.Sprintf("[`%s`](%s/blob/%s/%s)", path, opts.RepoURL, opts.SHA, path)
+		return fmt.Sprintf("[`%s`](%s)", path, blobURL(opts.RepoURL, opts.SHA, path))
 	}
 	return fmt.Sprintf("`%s`", path)
 }
 
+// blobURL links to a file at a commit. repoURL is the repository page on the
+// GitHub server, e.g. "https://ghe.example.com/owner/repo".
+func blobURL(repoURL, sha, filePath string) string {
+	return fmt.Sprintf("%s/blob/%s/%s", strings.TrimRight(repoURL, "/"), sha, filePath)
+}
+
+// RepoURL returns the web URL of a repository on a GitHub server. An empty
+// serverURL means github.com.
+func RepoURL(serverURL, repository string) string {
+	if serverURL == "" {
+		serverURL = "https://github.com"
+	}
+	return strings.TrimRight(serverURL, "/") + "/" + repository
+}
+
 func formatFooter() string {
 	return "---\n<sub>\U0001F4C8 Generated by [LiteCov](https://github.com/manashmandal/litecov)</sub>\n"
 }
 
-func getStatusEmoji(coverage float64) string {
-	switch {
-	case coverage >= 80:
-		return "\u2705"
-	case coverage >= 50:
-		return "\u26A0\uFE0F"
-	default:
-		return "\u274C"
+// indicator marks the bucket pct falls in.
+func (o Options) indicator(pct float64) string {
+	return o.Buckets.Indicator(pct, o.TextIndicators)
+}
+
+// lowestIndicator marks files without coverage data like the worst bucket.
+func (o Options) lowestIndicator() string {
+	if o.TextIndicators {
+		return o.Buckets.Lowest().Label
+	}
+	return o.Buckets.Lowest().Emoji
+}
+
+func formatNoTests(opts Options) string {
+	if opts.TextIndicators {
+		return "`no tests`"
 	}
+	return "`⚠️ no tests`"
 }
 
 func formatUncoveredLines(lines []int, repoURL, sha, filePath string) string {
@@ -367,9 +657,9 @@ func formatUncoveredLines(lines []int, repoURL, sha, filePath string) string {
 func formatRange(start, end int, repoURL, sha, filePath string) string {
 	if repoURL != "" && sha != "" {
 		if start == end {
-			return fmt.Sprintf("[L%d](%s/blob/%s/%s#L%d)", start, repoURL, sha, filePath, start)
+			return fmt.Sprintf("[L%d](%s#L%d)", start, blobURL(repoURL, sha, filePath), start)
 		}
-		return fmt.Sprintf("[L%d-%d](%s/blob/%s/%s#L%d-L%d)", start, end, repoURL, sha, filePath, start, end)
+		return fmt.Sprintf("[L%d-%d](%s#L%d-L%d)", start, end, blobURL(repoURL, sha, filePath), start, end)
 	}
 	if start == end {
 		return fmt.Sprintf("L%d", start)
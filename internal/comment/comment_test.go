@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/manashmandal/litecov/internal/coverage"
+	"github.com/manashmandal/litecov/internal/paths"
 )
 
 func TestFormat(t *testing.T) {
@@ -97,6 +98,29 @@ func TestFormat_FilterChanged(t *testing.T) {
 	}
 }
 
+func TestFormat_MissingFilesRespectFilter(t *testing.T) {
+	report := &coverage.Report{
+		Files: []coverage.FileCoverage{
+			{Path: "src/parser.go", LinesCovered: 75, LinesTotal: 100},
+		},
+	}
+
+	opts := Options{
+		ShowFiles:    "changed",
+		ChangedFiles: []string{"src/parser.go", "src/new.go", "db/migrations/0001.go"},
+		Filter:       paths.NewFilter(nil, []string{"**/migrations/**"}, true),
+	}
+
+	result := Format(report, opts)
+
+	if !strings.Contains(result, "src/new.go") {
+		t.Error("missing untested changed file new.go")
+	}
+	if strings.Contains(result, "db/migrations/0001.go") {
+		t.Error("excluded file should not be listed as untested")
+	}
+}
+
 func TestFormat_ChangedNoFilter(t *testing.T) {
 	report := &coverage.Report{
 		Files: []coverage.FileCoverage{
@@ -335,7 +359,7 @@ func TestFormatRange_SingleLineWithHyperlink(t *testing.T) {
 	}
 }
 
-func TestGetStatusEmoji(t *testing.T) {
+func TestOptionsIndicator(t *testing.T) {
 	tests := []struct {
 		coverage float64
 		expected string
@@ -349,13 +373,45 @@ func TestGetStatusEmoji(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		result := getStatusEmoji(tt.coverage)
+		result := Options{}.indicator(tt.coverage)
 		if result != tt.expected {
-			t.Errorf("getStatusEmoji(%.2f) = %s, expected %s", tt.coverage, result, tt.expected)
+			t.Errorf("indicator(%.2f) = %s, expected %s", tt.coverage, result, tt.expected)
 		}
 	}
 }
 
+func TestFormat_Buckets(t *testing.T) {
+	report := &coverage.Report{
+		Coverage:     75.0,
+		TotalCovered: 3,
+		TotalLines:   4,
+		Files: []coverage.FileCoverage{
+			{Path: "a.go", LinesCovered: 3, LinesTotal: 4, UncoveredLines: []int{4}},
+		},
+	}
+	buckets, err := coverage.ParseBuckets("90:green, 70:yellow, 0:red")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gates := []coverage.GateResult{{Gate: coverage.RatchetGate{Kind: coverage.GateProjectDrop}, Passed: true}}
+
+	result := Format(report, Options{ShowFiles: "all", Buckets: buckets, Gates: gates})
+	if !strings.Contains(result, "> \u26A0\uFE0F **Coverage:** `75.00%`") {
+		t.Errorf("75%% should be in the middle bucket:\n%s", result)
+	}
+
+	result = Format(report, Options{ShowFiles: "all", Buckets: buckets, Gates: gates, TextIndicators: true})
+	if !strings.Contains(result, "> fair **Coverage:** `75.00%`") {
+		t.Errorf("missing text indicator in summary:\n%s", result)
+	}
+	if !strings.Contains(result, "| L4 | fair |") || !strings.Contains(result, " | pass |") {
+		t.Errorf("missing text indicators in tables:\n%s", result)
+	}
+	if strings.ContainsAny(result, "\u2705\u274C\u26A0") {
+		t.Errorf("text indicators should not use emoji:\n%s", result)
+	}
+}
+
 func TestGroupConsecutiveLines(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -441,7 +497,7 @@ func TestFormatQuickSummary(t *testing.T) {
 		Files:        make([]coverage.FileCoverage, 10),
 	}
 
-	result := formatQuickSummary(report)
+	result := formatQuickSummary(report, Options{})
 
 	if !strings.Contains(result, "85.00%") {
 		t.Error("missing coverage percentage")
@@ -457,6 +513,120 @@ func TestFormatQuickSummary(t *testing.T) {
 	}
 }
 
+func TestFormatQuickSummary_Excluded(t *testing.T) {
+	report := &coverage.Report{
+		TotalCovered:  90,
+		TotalLines:    100,
+		Coverage:      90.0,
+		ExcludedLines: 7,
+	}
+
+	if result := formatQuickSummary(report, Options{}); !strings.Contains(result, "**Excluded:** `7`") {
+		t.Errorf("missing excluded lines count in %q", result)
+	}
+
+	report.ExcludedLines = 0
+	if result := formatQuickSummary(report, Options{}); strings.Contains(result, "Excluded") {
+		t.Error("should not mention exclusions when none were applied")
+	}
+}
+
+func TestFormat_Violations(t *testing.T) {
+	report := &coverage.Report{
+		Files: []coverage.FileCoverage{
+			{Path: "internal/billing/tax.go", LinesCovered: 60, LinesTotal: 100},
+		},
+		TotalCovered: 60,
+		TotalLines:   100,
+		Coverage:     60.0,
+	}
+
+	opts := Options{
+		ShowFiles: "all",
+		RepoURL:   "https://github.com/owner/repo",
+		SHA:       "abc123",
+		Violations: []coverage.Violation{
+			{Rule: "project", Coverage: 60, Minimum: 80},
+			{Rule: "internal/billing/**", Coverage: 60, Minimum: 90, Files: []string{"internal/billing/tax.go"}},
+		},
+	}
+
+	result := Format(report, opts)
+
+	checks := []string{
+		"Coverage thresholds not met",
+		"| Project | `60.00%` | `80.00%` | - |",
+		"| `internal/billing/**` | `60.00%` | `90.00%` | [`internal/billing/tax.go`](https://github.com/owner/repo/blob/abc123/internal/billing/tax.go) |",
+	}
+	for _, check := range checks {
+		if !strings.Contains(result, check) {
+			t.Errorf("missing %q in output", check)
+		}
+	}
+}
+
+func TestFormatViolations_Empty(t *testing.T) {
+	if result := formatViolations(nil, Options{}); result != "" {
+		t.Errorf("formatViolations(nil) = %q, want empty", result)
+	}
+}
+
+func TestFormatViolations_ManyFiles(t *testing.T) {
+	v := coverage.Violation{
+		Rule:     "file",
+		Coverage: 5,
+		Minimum:  30,
+		Files:    []string{"a.go", "b.go", "c.go", "d.go", "e.go", "f.go", "g.go"},
+	}
+	result := formatViolations([]coverage.Violation{v}, Options{})
+	if !strings.Contains(result, "| Any file |") {
+		t.Error("missing file rule label")
+	}
+	if !strings.Contains(result, "+2 more") {
+		t.Error("missing overflow count")
+	}
+}
+
+func TestFormatGates(t *testing.T) {
+	gates := []coverage.GateResult{
+		{Gate: coverage.RatchetGate{Kind: coverage.GateProjectDrop, Limit: 0.5}, Passed: true, Actual: 0.2},
+		{Gate: coverage.RatchetGate{Kind: coverage.GateNewFile, Limit: 60}, Passed: false, Actual: 40, Files: []string{"internal/c.go"}},
+	}
+
+	result := formatGates(gates, Options{})
+
+	checks := []string{
+		"Coverage Gates",
+		"| Project drop | +0.20% vs base (max drop 0.50%) | - | \u2705 |",
+		"| New files | 1 new files below 60.00%, lowest 40.00% | `internal/c.go` | \u274C |",
+	}
+	for _, check := range checks {
+		if !strings.Contains(result, check) {
+			t.Errorf("missing %q in output", check)
+		}
+	}
+}
+
+func TestFormatGates_Empty(t *testing.T) {
+	if result := formatGates(nil, Options{}); result != "" {
+		t.Errorf("formatGates(nil) = %q, want empty", result)
+	}
+}
+
+func TestFormatQuickSummary_Patch(t *testing.T) {
+	report := &coverage.Report{TotalCovered: 90, TotalLines: 100, Coverage: 90.0}
+
+	result := formatQuickSummary(report, Options{Patch: &coverage.Patch{Covered: 3, Total: 4}})
+	if !strings.Contains(result, "**Patch:** `75.00%`") {
+		t.Errorf("missing patch coverage in %q", result)
+	}
+
+	result = formatQuickSummary(report, Options{Patch: &coverage.Patch{}})
+	if strings.Contains(result, "Patch") {
+		t.Error("should not show patch coverage without coverable added lines")
+	}
+}
+
 func TestFormatCoverageDiff(t *testing.T) {
 	report := &coverage.Report{
 		TotalCovered: 500,
@@ -605,6 +775,77 @@ func TestFormatWithComparison(t *testing.T) {
 	}
 }
 
+func TestFormatWithComparison_UncoveredLines(t *testing.T) {
+	head := &coverage.Report{
+		Files: []coverage.FileCoverage{
+			{Path: "a.go", LinesCovered: 6, LinesTotal: 10, UncoveredLines: []int{3, 4, 8, 9}},
+		},
+	}
+	head.Calculate()
+	base := &coverage.Report{
+		Files: []coverage.FileCoverage{
+			{Path: "a.go", LinesCovered: 9, LinesTotal: 10, UncoveredLines: []int{3}},
+		},
+	}
+	base.Calculate()
+	opts := Options{RepoURL: "https://github.com/o/r", SHA: "abc"}
+
+	result := FormatWithComparison(coverage.NewComparison(head, base, nil), opts)
+	want := "| [`a.go`](https://github.com/o/r/blob/abc/a.go) | `60.00%` | `-30.00%` | `+3` | " +
+		"[L3-4](https://github.com/o/r/blob/abc/a.go#L3-L4), [L8-9](https://github.com/o/r/blob/abc/a.go#L8-L9) |"
+	if !strings.Contains(result, "| New Uncovered | Uncovered Lines |") || !strings.Contains(result, want) {
+		t.Errorf("expected all uncovered lines without a patch:\n%s", result)
+	}
+
+	comp := coverage.NewComparison(head, base, nil)
+	opts.Patch = &coverage.Patch{Covered: 1, Total: 3, Files: []coverage.PatchFile{
+		{Path: "a.go", Covered: 1, Total: 3, UncoveredLines: []int{8, 9}},
+	}}
+	comp.AddPatch(opts.Patch)
+	result = FormatWithComparison(comp, opts)
+	want = "| `+3` | [L8-9](https://github.com/o/r/blob/abc/a.go#L8-L9) |"
+	if !strings.Contains(result, "| Uncovered Added Lines |") || !strings.Contains(result, want) {
+		t.Errorf("expected only uncovered added lines with a patch:\n%s", result)
+	}
+}
+
+func TestFormatWithComparison_IndirectChanges(t *testing.T) {
+	head := &coverage.Report{Files: []coverage.FileCoverage{
+		{Path: "changed.go", LinesCovered: 5, LinesTotal: 10},
+		{Path: "small.go", LinesCovered: 9, LinesTotal: 10},
+		{Path: "large.go", LinesCovered: 2, LinesTotal: 10},
+	}}
+	head.Calculate()
+	base := &coverage.Report{Files: []coverage.FileCoverage{
+		{Path: "changed.go", LinesCovered: 1, LinesTotal: 10},
+		{Path: "small.go", LinesCovered: 8, LinesTotal: 10},
+		{Path: "large.go", LinesCovered: 8, LinesTotal: 10},
+	}}
+	base.Calculate()
+	comp := coverage.NewComparison(head, base, []string{"changed.go"})
+
+	result := FormatWithComparison(comp, Options{})
+	if !strings.Contains(result, "<summary>Indirect Changes (2)</summary>") {
+		t.Fatalf("missing indirect changes section:\n%s", result)
+	}
+	if !strings.Contains(result, "| `large.go` | `20.00%` | `-60.00%` |") {
+		t.Errorf("missing indirect change row:\n%s", result)
+	}
+
+	result = FormatWithComparison(comp, Options{IndirectThreshold: 10})
+	if !strings.Contains(result, "<summary>Indirect Changes (1)</summary>") || strings.Contains(result, "`small.go`") {
+		t.Errorf("changes within the threshold should be hidden:\n%s", result)
+	}
+	if len(comp.IndirectChanges) != 2 {
+		t.Error("the caller's comparison should not be modified")
+	}
+
+	result = FormatWithComparison(comp, Options{IndirectThreshold: 60})
+	if strings.Contains(result, "Indirect Changes") {
+		t.Errorf("section should be omitted without indirect changes:\n%s", result)
+	}
+}
+
 func TestFormatWithComparison_Nil(t *testing.T) {
 	result := FormatWithComparison(nil, Options{})
 	if result != "" {
@@ -718,7 +959,7 @@ func TestFormatQuickSummaryWithDelta(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := formatQuickSummaryWithDelta(tt.comp)
+			result := formatQuickSummaryWithDelta(tt.comp, Options{})
 
 			for _, check := range tt.contains {
 				if !strings.Contains(result, check) {
@@ -877,7 +1118,7 @@ func TestFormatFileDelta(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := formatFileDelta(tt.fc)
+			result := formatFileDelta(tt.fc, Options{})
 			if result != tt.expected {
 				t.Errorf("expected %s, got %s", tt.expected, result)
 			}
@@ -927,3 +1168,101 @@ func TestFormatDeltaString(t *testing.T) {
 		})
 	}
 }
+
+func TestRepoURL(t *testing.T) {
+	tests := []struct {
+		serverURL string
+		expected  string
+	}{
+		{"", "https://github.com/owner/repo"},
+		{"https://github.com", "https://github.com/owner/repo"},
+		{"https://ghe.example.com/", "https://ghe.example.com/owner/repo"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.serverURL, func(t *testing.T) {
+			if got := RepoURL(tt.serverURL, "owner/repo"); got != tt.expected {
+				t.Errorf("RepoURL() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBlobLinks_EnterpriseServer(t *testing.T) {
+	opts := Options{RepoURL: RepoURL("https://ghe.example.com", "owner/repo"), SHA: "abc123"}
+
+	name := formatFileName("src/a.go", opts)
+	if name != "[`src/a.go`](https://ghe.example.com/owner/repo/blob/abc123/src/a.go)" {
+		t.Errorf("formatFileName() = %v", name)
+	}
+
+	lines := formatRange(3, 5, opts.RepoURL, opts.SHA, "src/a.go")
+	if lines != "[L3-5](https://ghe.example.com/owner/repo/blob/abc123/src/a.go#L3-L5)" {
+		t.Errorf("formatRange() = %v", lines)
+	}
+}
+
+func TestFormat_Directories(t *testing.T) {
+	report := &coverage.Report{Files: []coverage.FileCoverage{
+		{Path: "internal/parser/lcov.go", LinesCovered: 9, LinesTotal: 10},
+		{Path: "internal/comment/comment.go", LinesCovered: 2, LinesTotal: 10},
+		{Path: "main.go", LinesCovered: 1, LinesTotal: 2},
+	}}
+	report.Calculate()
+
+	result := Format(report, Options{ShowFiles: "all"})
+	if strings.Contains(result, "Directories") {
+		t.Error("directory table should be off by default")
+	}
+
+	result = Format(report, Options{ShowFiles: "all", DirectoryDepth: 2, DirectorySort: coverage.SortByCoverage})
+	if !strings.Contains(result, "<summary>Directories (3)</summary>") {
+		t.Errorf("expected directory table, got:\n%s", result)
+	}
+	comment := strings.Index(result, "| `internal/comment/` | `20.00%` | 2/10 |")
+	root := strings.Index(result, "| `./` | `50.00%` | 1/2 |")
+	parser := strings.Index(result, "| `internal/parser/` | `90.00%` | 9/10 |")
+	if comment < 0 || root < 0 || parser < 0 {
+		t.Fatalf("missing directory rows:\n%s", result)
+	}
+	if !(comment < root && root < parser) {
+		t.Error("directories should be sorted by coverage, lowest first")
+	}
+}
+
+func TestFormatWithComparison_Directories(t *testing.T) {
+	head := &coverage.Report{Files: []coverage.FileCoverage{
+		{Path: "src/a/x.go", LinesCovered: 8, LinesTotal: 10},
+		{Path: "src/b/y.go", LinesCovered: 2, LinesTotal: 10},
+		{Path: "src/c/new.go", LinesCovered: 5, LinesTotal: 10},
+		{Path: "src/d/unchanged.go", LinesCovered: 1, LinesTotal: 10},
+	}}
+	head.Calculate()
+	base := &coverage.Report{Files: []coverage.FileCoverage{
+		{Path: "src/a/x.go", LinesCovered: 6, LinesTotal: 10},
+		{Path: "src/b/y.go", LinesCovered: 5, LinesTotal: 10},
+		{Path: "src/d/unchanged.go", LinesCovered: 9, LinesTotal: 10},
+	}}
+	base.Calculate()
+	changed := []string{"src/a/x.go", "src/b/y.go", "src/c/new.go"}
+	comp := coverage.NewComparison(head, base, changed)
+
+	result := FormatWithComparison(comp, Options{
+		ChangedFiles:   changed,
+		DirectoryDepth: 2,
+		DirectorySort:  coverage.SortByChange,
+	})
+
+	b := strings.Index(result, "| `src/b/` | `20.00%` | `-30.00%` | 2/10 |")
+	a := strings.Index(result, "| `src/a/` | `80.00%` | `+20.00%` | 8/10 |")
+	c := strings.Index(result, "| `src/c/` | `50.00%` | `new` | 5/10 |")
+	if a < 0 || b < 0 || c < 0 {
+		t.Fatalf("missing directory rows:\n%s", result)
+	}
+	if !(b < a && a < c) {
+		t.Error("directories should be sorted by change, largest drop first")
+	}
+	if strings.Contains(result, "| `src/d/` |") {
+		t.Error("unchanged directories should not be rolled up")
+	}
+}
@@ -0,0 +1,261 @@
+package comment
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/manashmandal/litecov/internal/coverage"
+)
+
+// MaxCommentSize is the longest issue comment GitHub accepts, in characters.
+const MaxCommentSize = 65536
+
+// render executes the comment template and prefixes the Marker. Comments
+// longer than limit are shrunk step by step: uncovered line links become
+// counts, then indirect changes and the least impacted file rows are
+// dropped, and as a last resort the text is cut. A truncated comment says so
+// at the end.
+func render(d *TemplateData, limit int) string {
+	return renderPrefixed(d, Marker+"\n", limit)
+}
+
+// renderPrefixed renders like render with prefix in place of the Marker.
+func renderPrefixed(d *TemplateData, prefix string, limit int) string {
+	build := func() string { return prefix + d.execute() }
+	notice := truncatedNotice("comment", d.opts.FullReportURL)
+	return shrink(d, build, characters, limit, notice, indirectRows(d), fileRows(d))
+}
+
+// shrink returns build's output for d, shortened to fit limit when it is
+// too long: uncovered line links become counts, then the least impacted of
+// rows are dropped, set by set, and as a last resort the text is cut. The
+// notice is appended to shortened output.
+func shrink(d *TemplateData, build func() string, unit sizeUnit, limit int, notice string, rows ...rowSet) string {
+	body := build()
+	if unit.len(body) <= limit {
+		return body
+	}
+
+	budget := limit - unit.len(notice)
+	fits := func() (string, bool) {
+		body := build()
+		return body, unit.len(body) <= budget
+	}
+
+	d.opts.compactLines = true
+	if body, ok := fits(); ok {
+		return body + notice
+	}
+
+	// Keep the most impacted rows, as many as fit, giving up earlier sets
+	// such as indirect changes before later ones
+	for _, rows := range rows {
+		keep := sort.Search(rows.Len()+1, func(n int) bool {
+			rows.keep(d, n)
+			_, ok := fits()
+			return !ok
+		}) - 1
+		if keep < 0 {
+			keep = 0
+		}
+		rows.keep(d, keep)
+		if body, ok := fits(); ok {
+			return body + notice
+		}
+	}
+	body, _ = fits()
+	return unit.cut(body, budget) + notice
+}
+
+// sizeUnit is the unit a GitHub size limit is counted in.
+type sizeUnit int
+
+const (
+	// characters limit comments and check runs.
+	characters sizeUnit = iota
+	// bytes limit job summaries.
+	bytes
+)
+
+func (u sizeUnit) len(s string) int {
+	if u == bytes {
+		return len(s)
+	}
+	return utf8.RuneCountInString(s)
+}
+
+// cut shortens s to at most limit units, ending at a line break.
+func (u sizeUnit) cut(s string, limit int) string {
+	if u == characters {
+		return cut(s, limit)
+	}
+	if limit <= 0 {
+		return ""
+	}
+	if len(s) > limit {
+		s = s[:limit]
+	}
+	if idx := strings.LastIndex(s, "\n"); idx > 0 {
+		s = s[:idx+1]
+	}
+	return s
+}
+
+// truncatedNotice tells readers that the text of kind, such as "comment",
+// was shortened, linking fullReportURL when set.
+func truncatedNotice(kind, fullReportURL string) string {
+	notice := fmt.Sprintf("\n> [!NOTE]\n> This %s was shortened to fit GitHub's size limit.", kind)
+	if fullReportURL != "" {
+		notice += fmt.Sprintf(" See the [full report](%s).", fullReportURL)
+	}
+	return notice + "\n"
+}
+
+// rowSet is the table rows of a comment, ordered from most to least
+// impacted. keep renders only the first n of them.
+type rowSet interface {
+	Len() int
+	keep(d *TemplateData, n int)
+}
+
+func fileRows(d *TemplateData) rowSet {
+	if d.Comparison != nil {
+		return newChangeRows(d.Comparison.FileChanges)
+	}
+	return newFileRows(d.Files)
+}
+
+// fileRowSet ranks files by uncovered lines, with untested files first.
+type fileRowSet struct {
+	files  []coverage.FileCoverage
+	ranked []int
+}
+
+func newFileRows(files []coverage.FileCoverage) *fileRowSet {
+	r := &fileRowSet{files: files, ranked: make([]int, len(files))}
+	for i := range files {
+		r.ranked[i] = i
+	}
+	impact := func(f coverage.FileCoverage) int {
+		if f.LinesTotal == 0 {
+			return math.MaxInt
+		}
+		return f.LinesTotal - f.LinesCovered
+	}
+	sort.SliceStable(r.ranked, func(i, j int) bool {
+		return impact(files[r.ranked[i]]) > impact(files[r.ranked[j]])
+	})
+	return r
+}
+
+func (r *fileRowSet) Len() int { return len(r.files) }
+
+func (r *fileRowSet) keep(d *TemplateData, n int) {
+	d.Files = pick(r.files, r.ranked[:n])
+	d.opts.omittedFiles = len(r.files) - n
+}
+
+// changeRowSet ranks file changes by the size of the change, with untested
+// files first.
+type changeRowSet struct {
+	changes []coverage.FileChange
+	ranked  []int
+}
+
+func newChangeRows(changes []coverage.FileChange) *changeRowSet {
+	r := &changeRowSet{changes: changes, ranked: make([]int, len(changes))}
+	for i := range changes {
+		r.ranked[i] = i
+	}
+	impact := func(fc coverage.FileChange) float64 {
+		switch {
+		case fc.NoCoverage:
+			return math.Inf(1)
+		case fc.IsNew:
+			return 100 - fc.HeadCoverage
+		case fc.Delta < 0:
+			return -fc.Delta
+		default:
+			return fc.Delta
+		}
+	}
+	sort.SliceStable(r.ranked, func(i, j int) bool {
+		return impact(changes[r.ranked[i]]) > impact(changes[r.ranked[j]])
+	})
+	return r
+}
+
+func (r *changeRowSet) Len() int { return len(r.changes) }
+
+func (r *changeRowSet) keep(d *TemplateData, n int) {
+	comp := *d.Comparison
+	comp.FileChanges = pick(r.changes, r.ranked[:n])
+	d.Comparison = &comp
+	d.opts.omittedFiles = len(r.changes) - n
+}
+
+// indirectRowSet keeps the largest indirect changes, which come first.
+type indirectRowSet struct {
+	changes []coverage.FileChange
+}
+
+func indirectRows(d *TemplateData) rowSet {
+	if d.Comparison == nil {
+		return indirectRowSet{}
+	}
+	return indirectRowSet{changes: d.Comparison.IndirectChanges}
+}
+
+func (r indirectRowSet) Len() int { return len(r.changes) }
+
+func (r indirectRowSet) keep(d *TemplateData, n int) {
+	if d.Comparison == nil {
+		return
+	}
+	comp := *d.Comparison
+	comp.IndirectChanges = r.changes[:n]
+	d.Comparison = &comp
+	d.opts.omittedIndirect = len(r.changes) - n
+}
+
+// pick returns the items at indexes, in their original order.
+func pick[T any](items []T, indexes []int) []T {
+	sorted := append([]int(nil), indexes...)
+	sort.Ints(sorted)
+	picked := make([]T, 0, len(sorted))
+	for _, i := range sorted {
+		picked = append(picked, items[i])
+	}
+	return picked
+}
+
+// formatOmittedFiles renders the row standing in for omitted table rows,
+// padded to the given number of columns.
+func formatOmittedFiles(omitted, columns int) string {
+	if omitted == 0 {
+		return ""
+	}
+	return fmt.Sprintf("| _+%d more files_ |%s\n", omitted, strings.Repeat(" |", columns-1))
+}
+
+// cut shortens s to at most limit characters, ending at a line break.
+func cut(s string, limit int) string {
+	if limit <= 0 {
+		return ""
+	}
+	runes := 0
+	for i := range s {
+		if runes == limit {
+			s = s[:i]
+			break
+		}
+		runes++
+	}
+	if idx := strings.LastIndex(s, "\n"); idx > 0 {
+		s = s[:idx+1]
+	}
+	return s
+}
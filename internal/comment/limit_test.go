@@ -0,0 +1,211 @@
+package comment
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/manashmandal/litecov/internal/coverage"
+)
+
+// scatteredLines returns n uncovered lines that each form their own range.
+func scatteredLines(n int) []int {
+	lines := make([]int, n)
+	for i := range lines {
+		lines[i] = i*2 + 1
+	}
+	return lines
+}
+
+func limitReport(files int) *coverage.Report {
+	report := &coverage.Report{}
+	for i := 0; i < files; i++ {
+		uncovered := scatteredLines(i%10 + 1)
+		report.Files = append(report.Files, coverage.FileCoverage{
+			Path:           fmt.Sprintf("pkg/file%03d.go", i),
+			LinesCovered:   20,
+			LinesTotal:     20 + len(uncovered),
+			UncoveredLines: uncovered,
+		})
+	}
+	report.Calculate()
+	return report
+}
+
+func limitOptions() Options {
+	return Options{
+		ShowFiles:     "all",
+		RepoURL:       "https://github.com/o/r",
+		SHA:           "0123456789abcdef0123456789abcdef01234567",
+		FullReportURL: "https://github.com/o/r/actions/runs/1",
+	}
+}
+
+func TestRender_UnderLimit(t *testing.T) {
+	report := limitReport(3)
+	d := newTemplateData(report, nil, limitOptions())
+	full := render(d, MaxCommentSize)
+	if strings.Contains(full, "shortened") {
+		t.Error("comment under the limit should not be shortened")
+	}
+	if full != Format(report, limitOptions()) {
+		t.Error("Format() should render at MaxCommentSize")
+	}
+}
+
+func TestRender_CompactsLines(t *testing.T) {
+	report := limitReport(5)
+	full := render(newTemplateData(report, nil, limitOptions()), MaxCommentSize)
+	limit := utf8.RuneCountInString(full) - 100
+
+	result := render(newTemplateData(report, nil, limitOptions()), limit)
+	if n := utf8.RuneCountInString(result); n > limit {
+		t.Errorf("comment has %d characters, limit %d", n, limit)
+	}
+	if strings.Contains(result, "#L1)") {
+		t.Error("uncovered line links should be collapsed")
+	}
+	if !strings.Contains(result, "| 5 lines |") {
+		t.Errorf("uncovered lines should be counted:\n%s", result)
+	}
+	if strings.Contains(result, "more files") {
+		t.Error("no rows should be dropped when collapsing lines is enough")
+	}
+	if !strings.Contains(result, "shortened to fit GitHub's size limit. See the [full report](https://github.com/o/r/actions/runs/1).") {
+		t.Errorf("truncation notice missing:\n%s", result)
+	}
+}
+
+func TestRender_DropsLowImpactRows(t *testing.T) {
+	report := limitReport(200)
+	limit := 8000
+
+	result := render(newTemplateData(report, nil, limitOptions()), limit)
+	if n := utf8.RuneCountInString(result); n > limit {
+		t.Errorf("comment has %d characters, limit %d", n, limit)
+	}
+	if !strings.Contains(result, "<summary>Impacted Files (200)</summary>") {
+		t.Error("summary should count every file")
+	}
+	if !strings.Contains(result, "more files_ |") {
+		t.Fatalf("expected a +N more files row:\n%s", result)
+	}
+	// Files with 10 uncovered lines are the most impacted
+	if !strings.Contains(result, "file009.go") || strings.Contains(result, "file000.go") {
+		t.Error("least impacted rows should be dropped first")
+	}
+	if !strings.HasSuffix(strings.TrimSpace(result), "(https://github.com/o/r/actions/runs/1).") {
+		t.Error("truncation notice should end the comment")
+	}
+}
+
+func TestRender_DropsSmallestChanges(t *testing.T) {
+	head := &coverage.Report{}
+	base := &coverage.Report{}
+	for i := 0; i < 300; i++ {
+		path := fmt.Sprintf("pkg/file%03d.go", i)
+		head.Files = append(head.Files, coverage.FileCoverage{Path: path, LinesCovered: 50, LinesTotal: 100})
+		base.Files = append(base.Files, coverage.FileCoverage{Path: path, LinesCovered: 50 + i%40, LinesTotal: 100})
+	}
+	head.Calculate()
+	base.Calculate()
+	comp := coverage.NewComparison(head, base, nil)
+
+	limit := 10000
+	result := render(newTemplateData(head, comp, limitOptions()), limit)
+	if n := utf8.RuneCountInString(result); n > limit {
+		t.Errorf("comment has %d characters, limit %d", n, limit)
+	}
+	if !strings.Contains(result, "`-39.00%`") || strings.Contains(result, "`50.00%` | `ø`") {
+		t.Errorf("largest changes should be kept and unchanged files dropped:\n%s", result)
+	}
+	if !strings.Contains(result, "more files_ | | | | | |\n") {
+		t.Errorf("omitted row should fill the 6 columns of the table:\n%s", result)
+	}
+	if len(comp.FileChanges) != 300 {
+		t.Error("the caller's comparison should not be modified")
+	}
+}
+
+func TestRender_DropsIndirectChangesFirst(t *testing.T) {
+	head := &coverage.Report{}
+	base := &coverage.Report{}
+	var changed []string
+	for i := 0; i < 300; i++ {
+		path := fmt.Sprintf("pkg/file%03d.go", i)
+		head.Files = append(head.Files, coverage.FileCoverage{Path: path, LinesCovered: 50, LinesTotal: 100})
+		base.Files = append(base.Files, coverage.FileCoverage{Path: path, LinesCovered: 51 + i%40, LinesTotal: 100})
+		if i < 5 {
+			changed = append(changed, path)
+		}
+	}
+	head.Calculate()
+	base.Calculate()
+	comp := coverage.NewComparison(head, base, changed)
+
+	limit := 10000
+	result := render(newTemplateData(head, comp, limitOptions()), limit)
+	if n := utf8.RuneCountInString(result); n > limit {
+		t.Errorf("comment has %d characters, limit %d", n, limit)
+	}
+	impacted := strings.Index(result, "<summary>Impacted Files (5)</summary>")
+	indirect := strings.Index(result, "<summary>Indirect Changes (295)</summary>")
+	if impacted < 0 || indirect < 0 {
+		t.Fatalf("missing file sections:\n%s", result)
+	}
+	if strings.Contains(result[impacted:indirect], "more files") {
+		t.Errorf("impacted files should all be kept:\n%s", result)
+	}
+	if !strings.Contains(result[indirect:], "more files_ | | | | |\n") {
+		t.Errorf("indirect changes should be shortened, filling the 5 columns:\n%s", result)
+	}
+	if len(comp.IndirectChanges) != 295 {
+		t.Error("the caller's comparison should not be modified")
+	}
+}
+
+func TestFormatCheckRun(t *testing.T) {
+	report := limitReport(300)
+	result := FormatCheckRun(report, nil, limitOptions(), 5000)
+	if n := utf8.RuneCountInString(result); n > 5000 {
+		t.Errorf("summary has %d characters, limit 5000", n)
+	}
+	if strings.Contains(result, Marker) {
+		t.Error("check run summary should not contain the comment marker")
+	}
+	if !strings.HasPrefix(result, formatHeader(Options{})) {
+		t.Errorf("summary should start with the header:\n%s", result)
+	}
+}
+
+func TestRender_CutsCustomTemplates(t *testing.T) {
+	tmpl, err := ParseTemplate(`{{range .Report.Files}}{{.Path}} is a long line of text
+{{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := limitOptions()
+	opts.Template = tmpl
+	opts.FullReportURL = ""
+
+	result := render(newTemplateData(limitReport(500), nil, opts), 2000)
+	if n := utf8.RuneCountInString(result); n > 2000 {
+		t.Errorf("comment has %d characters, limit 2000", n)
+	}
+	if !strings.HasSuffix(result, "shortened to fit GitHub's size limit.\n") {
+		t.Errorf("expected notice without link:\n%s", result)
+	}
+}
+
+func TestCut(t *testing.T) {
+	if got := cut("ab\ncd\nef", 7); got != "ab\ncd\n" {
+		t.Errorf("cut() = %q", got)
+	}
+	if got := cut("✅✅\n✅", 3); got != "✅✅\n" {
+		t.Errorf("cut() should count characters, got %q", got)
+	}
+	if got := cut("abc", 0); got != "" {
+		t.Errorf("cut() = %q", got)
+	}
+}
@@ -0,0 +1,40 @@
+package comment
+
+import "strings"
+
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders values as a row of block characters scaled between the
+// smallest and largest value. A flat series renders at mid height.
+func Sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		idx := len(sparkBars) / 2
+		if hi > lo {
+			idx = int((v-lo)/(hi-lo)*float64(len(sparkBars)-1) + 0.5)
+		}
+		sb.WriteRune(sparkBars[idx])
+	}
+	return sb.String()
+}
+
+// formatTrend shows the default branch history next to the headline number.
+func formatTrend(trend []float64) string {
+	if len(trend) < 2 {
+		return ""
+	}
+	return " `" + Sparkline(trend) + "`"
+}
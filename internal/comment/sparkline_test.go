@@ -0,0 +1,50 @@
+package comment
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/manashmandal/litecov/internal/coverage"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   string
+	}{
+		{"empty", nil, ""},
+		{"single", []float64{80}, "▅"},
+		{"flat", []float64{50, 50, 50}, "▅▅▅"},
+		{"rising", []float64{0, 50, 100}, "▁▅█"},
+		{"full range", []float64{1, 2, 3, 4, 5, 6, 7, 8}, "▁▂▃▄▅▆▇█"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sparkline(tt.values); got != tt.want {
+				t.Errorf("Sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormat_Trend(t *testing.T) {
+	report := &coverage.Report{TotalLines: 10, TotalCovered: 8, Coverage: 80}
+
+	result := Format(report, Options{Trend: []float64{70, 75, 80}})
+	if !strings.Contains(result, "`80.00%` `▁▅█`") {
+		t.Errorf("expected sparkline after coverage, got:\n%s", result)
+	}
+
+	result = Format(report, Options{Trend: []float64{80}})
+	if strings.Contains(result, "▅") {
+		t.Error("a single value should not render a trend")
+	}
+
+	comp := coverage.NewComparison(report, nil, nil)
+	result = FormatWithComparison(comp, Options{Trend: []float64{70, 80}})
+	if !strings.Contains(result, "`▁█`") {
+		t.Errorf("expected sparkline in comparison comment, got:\n%s", result)
+	}
+}
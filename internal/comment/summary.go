@@ -0,0 +1,65 @@
+package comment
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/manashmandal/litecov/internal/coverage"
+)
+
+// MaxSummarySize is the largest job summary GitHub Actions accepts per step,
+// in bytes.
+const MaxSummarySize = 1024 * 1024
+
+// FormatSummary renders the report for the Actions job summary. Unlike the
+// PR comment it lists every file, and it is shortened like the comment rather
+// than exceed MaxSummarySize. comp may be nil when there is no base report.
+func FormatSummary(report *coverage.Report, comp *coverage.Comparison, opts Options) string {
+	return formatSummary(report, comp, opts, MaxSummarySize)
+}
+
+func formatSummary(report *coverage.Report, comp *coverage.Comparison, opts Options, limit int) string {
+	if report == nil {
+		return ""
+	}
+
+	var head strings.Builder
+	head.WriteString(formatHeader(opts))
+	if comp != nil && comp.Head != nil {
+		head.WriteString(formatQuickSummaryWithDelta(comp, opts))
+	} else {
+		head.WriteString(formatQuickSummary(report, opts))
+	}
+	head.WriteString(formatViolations(opts.Violations, opts))
+	head.WriteString(formatGates(opts.Gates, opts))
+	if comp != nil && comp.Head != nil {
+		head.WriteString(formatCoverageDiffWithComparison(comp, opts))
+	} else {
+		head.WriteString(formatCoverageDiff(report))
+	}
+
+	files := make([]coverage.FileCoverage, len(report.Files))
+	copy(files, report.Files)
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	d := &TemplateData{Files: files, opts: opts}
+	build := func() string {
+		var sb strings.Builder
+		sb.WriteString(head.String())
+		if total := len(d.Files) + d.opts.omittedFiles; total > 0 {
+			sb.WriteString(fmt.Sprintf("### Files (%d)\n\n", total))
+			sb.WriteString("| File | Coverage | Uncovered Lines | Status |\n")
+			sb.WriteString("|------|----------|-----------------|--------|\n")
+			for _, f := range d.Files {
+				sb.WriteString(formatFileRow(f, d.opts))
+			}
+			sb.WriteString(formatOmittedFiles(d.opts.omittedFiles, 4))
+			sb.WriteString("\n")
+		}
+		sb.WriteString(formatFooter())
+		return sb.String()
+	}
+	// The full report link would point back at the summary itself
+	return shrink(d, build, bytes, limit, truncatedNotice("job summary", ""), newFileRows(files))
+}
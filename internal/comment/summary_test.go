@@ -0,0 +1,88 @@
+package comment
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/manashmandal/litecov/internal/coverage"
+)
+
+func TestFormatSummary(t *testing.T) {
+	report := &coverage.Report{
+		Files: []coverage.FileCoverage{
+			{Path: "src/b.go", LinesCovered: 5, LinesTotal: 10, UncoveredLines: []int{1, 2, 3, 4, 5}},
+			{Path: "src/a.go", LinesCovered: 10, LinesTotal: 10},
+		},
+	}
+	report.Calculate()
+
+	result := FormatSummary(report, nil, Options{Title: "Coverage", ShowFiles: "changed", ChangedFiles: []string{"src/a.go"}})
+
+	if strings.Contains(result, Marker) {
+		t.Error("summary should not contain the comment marker")
+	}
+	if !strings.Contains(result, "### Files (2)") {
+		t.Error("summary should list every file, not only changed ones")
+	}
+	a := strings.Index(result, "`src/a.go`")
+	b := strings.Index(result, "`src/b.go`")
+	if a < 0 || b < 0 || a > b {
+		t.Error("summary should list files sorted by path")
+	}
+	if !strings.Contains(result, "L1-5") {
+		t.Error("summary should show uncovered lines")
+	}
+}
+
+func TestFormatSummary_WithComparison(t *testing.T) {
+	head := &coverage.Report{Files: []coverage.FileCoverage{{Path: "a.go", LinesCovered: 8, LinesTotal: 10}}}
+	head.Calculate()
+	base := &coverage.Report{Files: []coverage.FileCoverage{{Path: "a.go", LinesCovered: 6, LinesTotal: 10}}}
+	base.Calculate()
+	comp := coverage.NewComparison(head, base, nil)
+
+	result := FormatSummary(head, comp, Options{})
+	if !strings.Contains(result, "+20.00%") {
+		t.Errorf("summary should show the delta versus base:\n%s", result)
+	}
+}
+
+func TestFormatSummary_Truncated(t *testing.T) {
+	report := &coverage.Report{}
+	for i := 0; i < 500; i++ {
+		report.Files = append(report.Files, coverage.FileCoverage{
+			Path:         fmt.Sprintf("src/file%03d.go", i),
+			LinesCovered: 1,
+			LinesTotal:   2,
+		})
+	}
+	report.Calculate()
+
+	limit := 4000
+	result := formatSummary(report, nil, Options{}, limit)
+
+	if len(result) > limit {
+		t.Errorf("summary is %d bytes, want at most %d", len(result), limit)
+	}
+	if !strings.Contains(result, "more files_ | | | |") {
+		t.Error("truncated summary should say files were left out")
+	}
+	if !strings.Contains(result, "This job summary was shortened to fit GitHub's size limit.") {
+		t.Error("truncated summary should end with the truncation notice")
+	}
+	if !strings.Contains(result, "Generated by") {
+		t.Error("truncated summary should keep the footer")
+	}
+	for _, line := range strings.Split(result, "\n") {
+		if strings.HasPrefix(line, "| `src/") && !strings.HasSuffix(line, "|") {
+			t.Errorf("row cut in the middle: %q", line)
+		}
+	}
+}
+
+func TestFormatSummary_NilReport(t *testing.T) {
+	if result := FormatSummary(nil, nil, Options{}); result != "" {
+		t.Errorf("FormatSummary(nil) = %q, want empty", result)
+	}
+}
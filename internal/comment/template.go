@@ -0,0 +1,275 @@
+package comment
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/manashmandal/litecov/internal/coverage"
+)
+
+//go:embed default.tmpl
+var defaultTemplateText string
+
+// rootTemplate is the template executed to render a comment.
+const rootTemplate = "comment"
+
+// TemplateData is the data comment templates are executed with.
+type TemplateData struct {
+	Title string
+	// Report is the head coverage report.
+	Report *coverage.Report
+	// Comparison compares Report with the base report, or is nil when there
+	// is no base coverage.
+	Comparison *coverage.Comparison
+	// Patch is the coverage of lines added in the pull request, or nil.
+	Patch *coverage.Patch
+	// Files are the files selected by show-files. Without a comparison this
+	// includes changed files that have no coverage data.
+	Files      []coverage.FileCoverage
+	Thresholds coverage.Thresholds
+	Violations []coverage.Violation
+	Gates      []coverage.GateResult
+	// Trend holds recent default branch coverage values, oldest first.
+	Trend      []float64
+	RepoURL    string
+	SHA        string
+	PRNumber   int
+	BaseBranch string
+
+	opts Options
+}
+
+// FileURL links to path at the head commit, or returns "" without a
+// repository URL.
+func (d *TemplateData) FileURL(path string) string {
+	if d.RepoURL == "" || d.SHA == "" {
+		return ""
+	}
+	return blobURL(d.RepoURL, d.SHA, path)
+}
+
+// Indicator returns the emoji, or text label, of the bucket pct falls in.
+func (d *TemplateData) Indicator(pct float64) string {
+	return d.opts.indicator(pct)
+}
+
+// Template renders the PR comment. Custom templates are layered over the
+// default one, so they can redefine single sections or the whole comment.
+type Template struct {
+	t *template.Template
+}
+
+// DefaultTemplate renders the standard LiteCov comment.
+var DefaultTemplate = mustParseTemplate()
+
+var templateFuncs = template.FuncMap{
+	// Default sections
+	"header":      func(d *TemplateData) string { return formatHeader(d.opts) },
+	"summary":     sectionSummary,
+	"violations":  func(d *TemplateData) string { return formatViolations(d.Violations, d.opts) },
+	"gates":       func(d *TemplateData) string { return formatGates(d.Gates, d.opts) },
+	"diff":        sectionDiff,
+	"directories": sectionDirectories,
+	"files":       sectionFiles,
+	"indirect":    sectionIndirect,
+	"footer":      func(d *TemplateData) string { return formatFooter() },
+
+	// Helpers for custom templates
+	"pct":       func(v float64) string { return fmt.Sprintf("%.2f%%", v) },
+	"delta":     func(v float64) string { return fmt.Sprintf("%+.2f%%", v) },
+	"sparkline": Sparkline,
+	"lines":     formatLineList,
+}
+
+func mustParseTemplate() *Template {
+	t := template.Must(template.New(rootTemplate).Funcs(templateFuncs).Parse(defaultTemplateText))
+	return &Template{t: t}
+}
+
+// ParseTemplate layers text over the default template. Top-level text
+// replaces the whole comment; {{define}} blocks replace the named sections:
+// header, summary, violations, gates, diff, directories, files, indirect and
+// footer.
+// The template is executed against sample data so that mistakes such as
+// unknown fields are reported here rather than when commenting.
+func ParseTemplate(text string) (*Template, error) {
+	t, err := DefaultTemplate.t.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := t.New(rootTemplate).Parse(text); err != nil {
+		return nil, fmt.Errorf("invalid comment template: %w", err)
+	}
+
+	tmpl := &Template{t: t}
+	for _, data := range sampleData() {
+		if err := tmpl.execute(io.Discard, data); err != nil {
+			return nil, fmt.Errorf("invalid comment template: %w", err)
+		}
+	}
+	return tmpl, nil
+}
+
+// LoadTemplate reads and validates a template file with ParseTemplate.
+func LoadTemplate(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	t, err := ParseTemplate(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return t, nil
+}
+
+// formatLineList formats line numbers as ranges, e.g. "1-3, 7".
+func formatLineList(lines []int) string {
+	var parts []string
+	for _, r := range GroupConsecutiveLines(lines) {
+		if r.Start == r.End {
+			parts = append(parts, fmt.Sprintf("%d", r.Start))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", r.Start, r.End))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (t *Template) execute(w io.Writer, data *TemplateData) error {
+	return t.t.ExecuteTemplate(w, rootTemplate, data)
+}
+
+// execute runs the template of opts, falling back to the default template
+// if it fails on this data.
+func (d *TemplateData) execute() string {
+	tmpl := d.opts.Template
+	if tmpl == nil {
+		tmpl = DefaultTemplate
+	}
+
+	var sb strings.Builder
+	if err := tmpl.execute(&sb, d); err != nil && tmpl != DefaultTemplate {
+		sb.Reset()
+		DefaultTemplate.execute(&sb, d)
+	}
+	return sb.String()
+}
+
+func newTemplateData(report *coverage.Report, comp *coverage.Comparison, opts Options) *TemplateData {
+	d := &TemplateData{
+		Title:      opts.Title,
+		Report:     report,
+		Comparison: comp,
+		Patch:      opts.Patch,
+		Thresholds: opts.Thresholds,
+		Violations: opts.Violations,
+		Gates:      opts.Gates,
+		Trend:      opts.Trend,
+		RepoURL:    opts.RepoURL,
+		SHA:        opts.SHA,
+		PRNumber:   opts.PRNumber,
+		BaseBranch: opts.BaseBranch,
+		opts:       opts,
+	}
+	if d.Title == "" {
+		d.Title = "Coverage Report"
+	}
+
+	if comp != nil && opts.IndirectThreshold > 0 {
+		// Copy rather than filter the caller's comparison
+		filtered := *comp
+		filtered.IndirectChanges = nil
+		for _, fc := range comp.IndirectChanges {
+			if math.Abs(fc.Delta) > opts.IndirectThreshold {
+				filtered.IndirectChanges = append(filtered.IndirectChanges, fc)
+			}
+		}
+		d.Comparison = &filtered
+	}
+
+	d.Files = filterFiles(report.Files, opts)
+	// Add files with no coverage when showing changed files
+	if comp == nil && opts.ShowFiles == "changed" && len(opts.ChangedFiles) > 0 {
+		for _, path := range findMissingFiles(report, opts.ChangedFiles, opts.Filter) {
+			d.Files = append(d.Files, coverage.FileCoverage{Path: path})
+		}
+	}
+	return d
+}
+
+func sectionSummary(d *TemplateData) string {
+	if d.Comparison != nil {
+		return formatQuickSummaryWithDelta(d.Comparison, d.opts)
+	}
+	return formatQuickSummary(d.Report, d.opts)
+}
+
+func sectionDiff(d *TemplateData) string {
+	if d.Comparison != nil {
+		return formatCoverageDiffWithComparison(d.Comparison, d.opts)
+	}
+	return formatCoverageDiff(d.Report)
+}
+
+func sectionDirectories(d *TemplateData) string {
+	if d.opts.DirectoryDepth <= 0 {
+		return ""
+	}
+	if d.Comparison != nil {
+		head, base := comparisonFiles(d.Comparison)
+		return formatDirectories(coverage.NewTreeWithBase(head, base), d.Comparison.Base != nil, d.opts)
+	}
+	return formatDirectories(coverage.NewTree(d.Files), false, d.opts)
+}
+
+func sectionIndirect(d *TemplateData) string {
+	if d.Comparison == nil {
+		return ""
+	}
+	return formatIndirectChanges(d.Comparison.IndirectChanges, d.opts)
+}
+
+func sectionFiles(d *TemplateData) string {
+	if d.Comparison != nil {
+		return formatImpactedFilesWithDelta(d.Comparison.FileChanges, d.opts)
+	}
+	return formatImpactedFiles(d.Files, d.opts)
+}
+
+// sampleData returns data covering every field templates may use, with and
+// without a base comparison.
+func sampleData() []*TemplateData {
+	head := &coverage.Report{Files: []coverage.FileCoverage{{
+		Path:           "src/main.go",
+		LinesCovered:   3,
+		LinesTotal:     4,
+		CoveredLines:   []int{1, 2, 3},
+		UncoveredLines: []int{4},
+	}}}
+	head.Calculate()
+	base := &coverage.Report{Files: []coverage.FileCoverage{{Path: "src/main.go", LinesCovered: 1, LinesTotal: 4}}}
+	base.Calculate()
+
+	opts := Options{
+		ShowFiles:  "all",
+		RepoURL:    "https://github.com/owner/repo",
+		SHA:        "0000000",
+		PRNumber:   1,
+		BaseBranch: "main",
+		Patch: &coverage.Patch{Covered: 1, Total: 2, Files: []coverage.PatchFile{
+			{Path: "src/main.go", Covered: 1, Total: 2, UncoveredLines: []int{4}},
+		}},
+		Violations: []coverage.Violation{{Rule: "project", Coverage: 75, Minimum: 80}},
+		Trend:      []float64{70, 75},
+	}
+	return []*TemplateData{
+		newTemplateData(head, nil, opts),
+		newTemplateData(head, coverage.NewComparison(head, base, nil), opts),
+	}
+}
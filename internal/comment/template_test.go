@@ -0,0 +1,128 @@
+package comment
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/manashmandal/litecov/internal/coverage"
+)
+
+func templateReport() *coverage.Report {
+	report := &coverage.Report{Files: []coverage.FileCoverage{
+		{Path: "src/a.go", LinesCovered: 8, LinesTotal: 10, UncoveredLines: []int{3, 4, 9}},
+	}}
+	report.Calculate()
+	return report
+}
+
+func TestParseTemplate_OverrideSection(t *testing.T) {
+	tmpl, err := ParseTemplate(`{{define "footer"}}Custom footer for PR #{{.PRNumber}}{{end}}`)
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+
+	result := Format(templateReport(), Options{Template: tmpl, PRNumber: 42})
+	if !strings.HasPrefix(result, Marker+"\n") {
+		t.Error("comment should start with the marker")
+	}
+	if !strings.HasSuffix(result, "Custom footer for PR #42") {
+		t.Errorf("footer not overridden:\n%s", result)
+	}
+	if !strings.Contains(result, "**Coverage:** `80.00%`") {
+		t.Error("other sections should keep their default output")
+	}
+}
+
+func TestParseTemplate_ReplaceComment(t *testing.T) {
+	text := `{{$.Indicator .Report.Coverage}} {{.Title}}: {{pct .Report.Coverage}}
+{{- with .Comparison}} ({{delta .CoverageDelta}}){{end}}
+{{range .Files}}- [{{.Path}}]({{$.FileURL .Path}}) missing {{lines .UncoveredLines}}
+{{end}}{{template "footer" .}}`
+	tmpl, err := ParseTemplate(text)
+	if err != nil {
+		t.Fatalf("ParseTemplate() error = %v", err)
+	}
+
+	opts := Options{
+		Template:  tmpl,
+		Title:     "Cov",
+		ShowFiles: "all",
+		RepoURL:   "https://github.com/o/r",
+		SHA:       "abc",
+	}
+	result := Format(templateReport(), opts)
+	want := Marker + "\n✅ Cov: 80.00%\n- [src/a.go](https://github.com/o/r/blob/abc/src/a.go) missing 3-4, 9\n" + formatFooter()
+	if result != want {
+		t.Errorf("Format() =\n%s\nwant\n%s", result, want)
+	}
+
+	base := &coverage.Report{Files: []coverage.FileCoverage{{Path: "src/a.go", LinesCovered: 5, LinesTotal: 10}}}
+	base.Calculate()
+	result = FormatWithComparison(coverage.NewComparison(templateReport(), base, nil), opts)
+	if !strings.Contains(result, "Cov: 80.00% (+30.00%)") {
+		t.Errorf("comparison data missing:\n%s", result)
+	}
+}
+
+func TestParseTemplate_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"syntax", `{{if .Report}}unterminated`, "unexpected EOF"},
+		{"unknown field", `{{.Reprt.Coverage}}`, "can't evaluate field Reprt"},
+		{"unknown function", `{{percent .Report.Coverage}}`, `function "percent" not defined`},
+		{"unguarded comparison", `{{.Comparison.CoverageDelta}}`, "nil pointer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTemplate(tt.text)
+			if err == nil {
+				t.Fatal("ParseTemplate() should fail")
+			}
+			if !strings.Contains(err.Error(), "invalid comment template") || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "comment.tmpl")
+	if err := os.WriteFile(path, []byte(`{{define "header"}}# Coverage{{"\n"}}{{end}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := LoadTemplate(path)
+	if err != nil {
+		t.Fatalf("LoadTemplate() error = %v", err)
+	}
+	if result := Format(templateReport(), Options{Template: tmpl}); !strings.Contains(result, Marker+"\n# Coverage\n> ") {
+		t.Errorf("header not overridden:\n%s", result)
+	}
+
+	if err := os.WriteFile(path, []byte(`{{.Missing}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTemplate(path); err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("LoadTemplate() error = %v, want it to name the file", err)
+	}
+	if _, err := LoadTemplate(filepath.Join(t.TempDir(), "missing.tmpl")); err == nil {
+		t.Error("LoadTemplate() should fail for a missing file")
+	}
+}
+
+func TestDefaultTemplate_MatchesSections(t *testing.T) {
+	report := templateReport()
+	opts := Options{ShowFiles: "all", Violations: []coverage.Violation{{Rule: "project", Coverage: 80, Minimum: 90}}}
+
+	want := Marker + "\n" + formatHeader(opts) + formatQuickSummary(report, opts) +
+		formatViolations(opts.Violations, opts) + formatCoverageDiff(report) +
+		formatImpactedFiles(report.Files, opts) + formatFooter()
+	if got := Format(report, opts); got != want {
+		t.Errorf("default template output differs from the sections:\n%s\nwant\n%s", got, want)
+	}
+}
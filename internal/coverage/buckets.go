@@ -0,0 +1,158 @@
+package coverage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/manashmandal/litecov/internal/paths"
+)
+
+// Bucket is a coverage range with the colour and indicators shown for it.
+type Bucket struct {
+	// Min is the lowest coverage in the bucket, in percent.
+	Min float64
+	// Color is a hex colour used by badges and the HTML report.
+	Color string
+	// Emoji and Label indicate the bucket in text, Label being the plain
+	// text alternative to Emoji.
+	Emoji string
+	Label string
+}
+
+// Buckets are coverage ranges ordered by descending Min.
+type Buckets []Bucket
+
+// DefaultBuckets mark coverage from 80% as good and from 50% as fair.
+var DefaultBuckets = Buckets{
+	{Min: 80, Color: "#4c1", Emoji: "✅", Label: "good"},
+	{Min: 50, Color: "#dfb317", Emoji: "⚠️", Label: "fair"},
+	{Min: 0, Color: "#e05d44", Emoji: "❌", Label: "poor"},
+}
+
+// colors are the named colours accepted by ParseBuckets, as on shields.io.
+var colors = map[string]string{
+	"brightgreen": "#4c1",
+	"green":       "#97ca00",
+	"yellowgreen": "#a4a61d",
+	"yellow":      "#dfb317",
+	"orange":      "#fe7d37",
+	"red":         "#e05d44",
+	"blue":        "#007ec6",
+	"grey":        "#555",
+	"lightgrey":   "#9f9f9f",
+}
+
+// ParseBuckets parses comma or newline separated "min:colour[:emoji[:label]]"
+// buckets such as "90:brightgreen, 70:yellow, 0:red". Colours are shields.io
+// names or hex values. Missing indicators default by rank: the highest
+// bucket is good, the lowest poor and any others fair. An empty string
+// returns the DefaultBuckets.
+func ParseBuckets(s string) (Buckets, error) {
+	var buckets Buckets
+	for _, entry := range paths.SplitPatterns(s) {
+		fields := strings.Split(entry, ":")
+		if len(fields) < 2 || len(fields) > 4 {
+			return nil, fmt.Errorf("invalid bucket %q: expected min:colour[:emoji[:label]]", entry)
+		}
+		min, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(fields[0]), "%"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket %q: bad minimum", entry)
+		}
+		color, err := parseColor(strings.TrimSpace(fields[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket %q: %w", entry, err)
+		}
+		b := Bucket{Min: min, Color: color}
+		if len(fields) > 2 {
+			b.Emoji = strings.TrimSpace(fields[2])
+		}
+		if len(fields) > 3 {
+			b.Label = strings.TrimSpace(fields[3])
+		}
+		buckets = append(buckets, b)
+	}
+	if len(buckets) == 0 {
+		return DefaultBuckets, nil
+	}
+
+	sortBuckets(buckets)
+	for i := range buckets {
+		rank := 1
+		switch i {
+		case 0:
+			rank = 0
+		case len(buckets) - 1:
+			rank = 2
+		}
+		if buckets[i].Emoji == "" {
+			buckets[i].Emoji = DefaultBuckets[rank].Emoji
+		}
+		if buckets[i].Label == "" {
+			buckets[i].Label = DefaultBuckets[rank].Label
+		}
+	}
+	return buckets, nil
+}
+
+func sortBuckets(buckets Buckets) {
+	for i := 1; i < len(buckets); i++ {
+		for j := i; j > 0 && buckets[j].Min > buckets[j-1].Min; j-- {
+			buckets[j], buckets[j-1] = buckets[j-1], buckets[j]
+		}
+	}
+}
+
+func parseColor(s string) (string, error) {
+	if c, ok := colors[strings.ToLower(s)]; ok {
+		return c, nil
+	}
+	hex := strings.TrimPrefix(s, "#")
+	if n := len(hex); n != 3 && n != 6 {
+		return "", fmt.Errorf("unknown colour %q", s)
+	}
+	if _, err := strconv.ParseUint(hex, 16, 32); err != nil {
+		return "", fmt.Errorf("unknown colour %q", s)
+	}
+	return "#" + strings.ToLower(hex), nil
+}
+
+// Rank returns the index of the bucket pct falls in: the first one whose
+// Min it reaches, or the last bucket when it reaches none.
+func (b Buckets) Rank(pct float64) int {
+	if len(b) == 0 {
+		return DefaultBuckets.Rank(pct)
+	}
+	for i, bucket := range b {
+		if pct >= bucket.Min {
+			return i
+		}
+	}
+	return len(b) - 1
+}
+
+// Find returns the bucket pct falls in.
+func (b Buckets) Find(pct float64) Bucket {
+	if len(b) == 0 {
+		b = DefaultBuckets
+	}
+	return b[b.Rank(pct)]
+}
+
+// Lowest returns the bucket for the worst coverage.
+func (b Buckets) Lowest() Bucket {
+	if len(b) == 0 {
+		b = DefaultBuckets
+	}
+	return b[len(b)-1]
+}
+
+// Indicator returns the emoji of the bucket pct falls in, or its label when
+// text is set.
+func (b Buckets) Indicator(pct float64, text bool) string {
+	bucket := b.Find(pct)
+	if text {
+		return bucket.Label
+	}
+	return bucket.Emoji
+}
@@ -0,0 +1,94 @@
+package coverage
+
+import "testing"
+
+func TestParseBuckets(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Buckets
+		wantErr bool
+	}{
+		{name: "empty", input: "", want: DefaultBuckets},
+		{
+			name:  "sorted with default indicators",
+			input: "0:red, 90:brightgreen, 70%:#ABC",
+			want: Buckets{
+				{Min: 90, Color: "#4c1", Emoji: "✅", Label: "good"},
+				{Min: 70, Color: "#abc", Emoji: "⚠️", Label: "fair"},
+				{Min: 0, Color: "#e05d44", Emoji: "❌", Label: "poor"},
+			},
+		},
+		{
+			name:  "custom indicators",
+			input: "75:green:🟢:pass\n0:orange:🟠",
+			want: Buckets{
+				{Min: 75, Color: "#97ca00", Emoji: "🟢", Label: "pass"},
+				{Min: 0, Color: "#fe7d37", Emoji: "🟠", Label: "poor"},
+			},
+		},
+		{name: "missing colour", input: "90", wantErr: true},
+		{name: "too many fields", input: "90:green:a:b:c", wantErr: true},
+		{name: "bad minimum", input: "high:green", wantErr: true},
+		{name: "unknown colour", input: "90:purple", wantErr: true},
+		{name: "bad hex", input: "90:#12345", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBuckets(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseBuckets(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseBuckets(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("bucket %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBuckets_Find(t *testing.T) {
+	tests := []struct {
+		pct   float64
+		rank  int
+		emoji string
+		label string
+	}{
+		{100, 0, "✅", "good"},
+		{80, 0, "✅", "good"},
+		{79.9, 1, "⚠️", "fair"},
+		{50, 1, "⚠️", "fair"},
+		{10, 2, "❌", "poor"},
+	}
+
+	for _, tt := range tests {
+		if got := DefaultBuckets.Rank(tt.pct); got != tt.rank {
+			t.Errorf("Rank(%v) = %d, want %d", tt.pct, got, tt.rank)
+		}
+		if got := DefaultBuckets.Indicator(tt.pct, false); got != tt.emoji {
+			t.Errorf("Indicator(%v) = %s, want %s", tt.pct, got, tt.emoji)
+		}
+		if got := DefaultBuckets.Indicator(tt.pct, true); got != tt.label {
+			t.Errorf("Indicator(%v, text) = %s, want %s", tt.pct, got, tt.label)
+		}
+	}
+
+	buckets := Buckets{{Min: 90, Color: "#4c1"}, {Min: 50, Color: "#dfb317"}}
+	if got := buckets.Find(20); got.Color != "#dfb317" {
+		t.Errorf("below every bucket should use the last one, got %+v", got)
+	}
+	if got := Buckets(nil).Find(85); got != DefaultBuckets[0] {
+		t.Errorf("nil buckets should use the defaults, got %+v", got)
+	}
+	if got := buckets.Lowest(); got.Min != 50 {
+		t.Errorf("Lowest() = %+v", got)
+	}
+}
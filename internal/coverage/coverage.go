@@ -1,6 +1,11 @@
 package coverage
 
-import "github.com/manashmandal/litecov/internal/paths"
+import (
+	"math"
+	"sort"
+
+	"github.com/manashmandal/litecov/internal/paths"
+)
 
 type FileCoverage struct {
 	Path           string
@@ -8,6 +13,12 @@ type FileCoverage struct {
 	LinesTotal     int
 	UncoveredLines []int
 	CoveredLines   []int
+	// ExcludedLines counts measured lines dropped by inline exclusion markers.
+	ExcludedLines int
+	// LineHits maps measured lines to their execution counts.
+	LineHits map[int]int
+	// PartialLines are covered lines with at least one branch never taken.
+	PartialLines []int
 }
 
 func (fc *FileCoverage) Percentage() float64 {
@@ -22,6 +33,8 @@ type Report struct {
 	TotalCovered int
 	TotalLines   int
 	Coverage     float64
+	// ExcludedLines counts measured lines dropped by inline exclusion markers.
+	ExcludedLines int
 }
 
 func (r *Report) Calculate() {
@@ -38,6 +51,30 @@ func (r *Report) Calculate() {
 	r.Coverage = float64(r.TotalCovered) / float64(r.TotalLines) * 100
 }
 
+// Filter drops files for which keep returns false and recalculates the totals.
+func (r *Report) Filter(keep func(path string) bool) {
+	files := r.Files[:0]
+	for _, f := range r.Files {
+		if keep(f.Path) {
+			files = append(files, f)
+		}
+	}
+	r.Files = files
+	r.Calculate()
+}
+
+// Subset returns a new report containing the files for which keep returns true.
+func (r *Report) Subset(keep func(path string) bool) *Report {
+	subset := &Report{}
+	for _, f := range r.Files {
+		if keep(f.Path) {
+			subset.Files = append(subset.Files, f)
+		}
+	}
+	subset.Calculate()
+	return subset
+}
+
 func (r *Report) Hits() int {
 	return r.TotalCovered
 }
@@ -52,6 +89,10 @@ type Comparison struct {
 	Base          *Report
 	CoverageDelta float64
 	FileChanges   []FileChange
+	// IndirectChanges are the files outside the changed files whose
+	// coverage differs from base, largest change first. They are only found
+	// when changed files are given, see FindIndirectChanges.
+	IndirectChanges []FileChange
 }
 
 // FileChange represents coverage change for a single file
@@ -62,11 +103,43 @@ type FileChange struct {
 	Delta        float64
 	IsNew        bool
 	NoCoverage   bool // True if file has no coverage data (completely untested)
+	// UncoveredLines are the uncovered lines of the head file, and
+	// NewlyUncovered how many more lines are uncovered than in base.
+	UncoveredLines []int
+	NewlyUncovered int
+	// UncoveredAdded are the uncovered lines added by the pull request, set
+	// by AddPatch.
+	UncoveredAdded []int
+}
+
+// AddPatch records the uncovered added lines of patch on the matching file
+// changes.
+func (c *Comparison) AddPatch(patch *Patch) {
+	if patch == nil {
+		return
+	}
+	patchFiles := make(map[string]bool, len(patch.Files))
+	byPath := make(map[string]PatchFile, len(patch.Files))
+	for _, pf := range patch.Files {
+		patchFiles[pf.Path] = true
+		byPath[pf.Path] = pf
+	}
+	for i := range c.FileChanges {
+		if matched := paths.FindMatchingChangedFile(c.FileChanges[i].Path, patchFiles); matched != "" {
+			c.FileChanges[i].UncoveredAdded = byPath[matched].UncoveredLines
+		}
+	}
 }
 
 // NewComparison creates a comparison between head and base reports
 // changedFiles is optional list of file paths that changed in the PR
 func NewComparison(head, base *Report, changedFiles []string) *Comparison {
+	return NewComparisonWithFilter(head, base, changedFiles, nil)
+}
+
+// NewComparisonWithFilter creates a comparison like NewComparison, using filter
+// to decide which changed files without coverage data are reported as untested.
+func NewComparisonWithFilter(head, base *Report, changedFiles []string, filter *paths.Filter) *Comparison {
 	if head == nil {
 		return &Comparison{}
 	}
@@ -119,12 +192,15 @@ func NewComparison(head, base *Report, changedFiles []string) *Comparison {
 		}
 
 		fc := FileChange{
-			Path:         filePath,
-			HeadCoverage: headFile.Percentage(),
+			Path:           filePath,
+			HeadCoverage:   headFile.Percentage(),
+			UncoveredLines: headFile.UncoveredLines,
+			NewlyUncovered: len(headFile.UncoveredLines),
 		}
 
 		if baseFile, exists := baseFileMap[headFile.Path]; exists {
 			fc.BaseCoverage = baseFile.Percentage()
+			fc.NewlyUncovered -= len(baseFile.UncoveredLines)
 			fc.IsNew = false
 		} else {
 			fc.BaseCoverage = 0
@@ -142,7 +218,7 @@ func NewComparison(head, base *Report, changedFiles []string) *Comparison {
 				continue
 			}
 			// Only include source files that should have coverage
-			if !paths.IsSourceFile(changedFile) {
+			if !filter.IsSourceFile(changedFile) {
 				continue
 			}
 			fc := FileChange{
@@ -163,9 +239,61 @@ func NewComparison(head, base *Report, changedFiles []string) *Comparison {
 		}
 	}
 
+	if filterByChanged {
+		comp.FindIndirectChanges(changedFiles)
+	}
+
 	return comp
 }
 
+// FindIndirectChanges sets IndirectChanges to the head files outside
+// changedFiles, the files changed by the pull request, whose coverage differs
+// from base. It lets the comparison list other files than those changed,
+// such as with show-files: all, and still find indirect changes.
+func (c *Comparison) FindIndirectChanges(changedFiles []string) {
+	c.IndirectChanges = nil
+	if c.Head == nil || c.Base == nil || len(changedFiles) == 0 {
+		return
+	}
+
+	changedFileSet := make(map[string]bool, len(changedFiles))
+	for _, f := range changedFiles {
+		changedFileSet[f] = true
+	}
+	baseFileMap := make(map[string]*FileCoverage, len(c.Base.Files))
+	for i := range c.Base.Files {
+		baseFileMap[c.Base.Files[i].Path] = &c.Base.Files[i]
+	}
+
+	for _, headFile := range c.Head.Files {
+		if paths.FindMatchingChangedFile(headFile.Path, changedFileSet) != "" {
+			continue
+		}
+		if fc, ok := indirectChange(headFile, baseFileMap[headFile.Path]); ok {
+			c.IndirectChanges = append(c.IndirectChanges, fc)
+		}
+	}
+	sort.SliceStable(c.IndirectChanges, func(i, j int) bool {
+		return math.Abs(c.IndirectChanges[i].Delta) > math.Abs(c.IndirectChanges[j].Delta)
+	})
+}
+
+// indirectChange compares an unchanged head file with its base version. It
+// returns false when the file is not in base or its coverage is the same.
+func indirectChange(headFile FileCoverage, baseFile *FileCoverage) (FileChange, bool) {
+	if baseFile == nil {
+		return FileChange{}, false
+	}
+	fc := FileChange{
+		Path:           headFile.Path,
+		HeadCoverage:   headFile.Percentage(),
+		BaseCoverage:   baseFile.Percentage(),
+		UncoveredLines: headFile.UncoveredLines,
+		NewlyUncovered: len(headFile.UncoveredLines) - len(baseFile.UncoveredLines),
+	}
+	fc.Delta = fc.HeadCoverage - fc.BaseCoverage
+	return fc, fc.Delta != 0
+}
 
 // findFileInReport finds a file in a report by path suffix matching
 func findFileInReport(report *Report, path string) *FileCoverage {
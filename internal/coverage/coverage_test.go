@@ -1,6 +1,9 @@
 package coverage
 
-import "testing"
+import (
+	"math"
+	"testing"
+)
 
 func TestFileCoverage_Percentage(t *testing.T) {
 	tests := []struct {
@@ -105,6 +108,48 @@ func TestFileCoverage_Path(t *testing.T) {
 	}
 }
 
+func TestReport_Filter(t *testing.T) {
+	report := &Report{
+		Files: []FileCoverage{
+			{Path: "internal/a.go", LinesCovered: 80, LinesTotal: 100},
+			{Path: "internal/mocks/mock_b.go", LinesCovered: 0, LinesTotal: 100},
+		},
+	}
+	report.Calculate()
+	report.Filter(func(path string) bool {
+		return path != "internal/mocks/mock_b.go"
+	})
+
+	if len(report.Files) != 1 {
+		t.Fatalf("got %d files, want 1", len(report.Files))
+	}
+	if report.TotalLines != 100 {
+		t.Errorf("TotalLines = %v, want 100", report.TotalLines)
+	}
+	if report.Coverage != 80.0 {
+		t.Errorf("Coverage = %v, want 80", report.Coverage)
+	}
+}
+
+func TestReport_Subset(t *testing.T) {
+	report := &Report{
+		Files: []FileCoverage{
+			{Path: "web/app.ts", LinesCovered: 50, LinesTotal: 100},
+			{Path: "internal/a.go", LinesCovered: 90, LinesTotal: 100},
+		},
+	}
+	report.Calculate()
+
+	subset := report.Subset(func(path string) bool { return path == "internal/a.go" })
+
+	if len(subset.Files) != 1 || subset.Coverage != 90 {
+		t.Errorf("Subset() = %d files at %.2f%%, want 1 file at 90%%", len(subset.Files), subset.Coverage)
+	}
+	if len(report.Files) != 2 {
+		t.Error("Subset() should not modify the original report")
+	}
+}
+
 func TestReport_Hits(t *testing.T) {
 	report := &Report{
 		TotalCovered: 75,
@@ -309,6 +354,137 @@ func TestFileChange_Delta(t *testing.T) {
 	}
 }
 
+func TestNewComparison_UncoveredLines(t *testing.T) {
+	head := &Report{
+		Files: []FileCoverage{
+			{Path: "a.go", LinesCovered: 6, LinesTotal: 10, UncoveredLines: []int{3, 4, 8, 9}},
+			{Path: "b.go", LinesCovered: 8, LinesTotal: 10, UncoveredLines: []int{1, 2}},
+			{Path: "c.go", LinesCovered: 1, LinesTotal: 2, UncoveredLines: []int{5}},
+		},
+	}
+	base := &Report{
+		Files: []FileCoverage{
+			{Path: "a.go", LinesCovered: 9, LinesTotal: 10, UncoveredLines: []int{3}},
+			{Path: "b.go", LinesCovered: 5, LinesTotal: 10, UncoveredLines: []int{1, 2, 3, 4, 5}},
+		},
+	}
+
+	comp := NewComparison(head, base, nil)
+
+	tests := []struct {
+		path           string
+		uncovered      int
+		newlyUncovered int
+	}{
+		{"a.go", 4, 3},
+		{"b.go", 2, -3},
+		{"c.go", 1, 1},
+	}
+	for i, tt := range tests {
+		fc := comp.FileChanges[i]
+		if fc.Path != tt.path || len(fc.UncoveredLines) != tt.uncovered || fc.NewlyUncovered != tt.newlyUncovered {
+			t.Errorf("FileChanges[%d] = %+v, want %s with %d uncovered, %d newly uncovered",
+				i, fc, tt.path, tt.uncovered, tt.newlyUncovered)
+		}
+	}
+}
+
+func TestNewComparison_IndirectChanges(t *testing.T) {
+	head := &Report{
+		Files: []FileCoverage{
+			{Path: "changed.go", LinesCovered: 5, LinesTotal: 10},
+			{Path: "small.go", LinesCovered: 9, LinesTotal: 10, UncoveredLines: []int{7}},
+			{Path: "same.go", LinesCovered: 5, LinesTotal: 10},
+			{Path: "large.go", LinesCovered: 2, LinesTotal: 10, UncoveredLines: []int{3, 4, 5, 6, 7, 8, 9, 10}},
+			{Path: "new.go", LinesCovered: 1, LinesTotal: 10},
+		},
+	}
+	base := &Report{
+		Files: []FileCoverage{
+			{Path: "changed.go", LinesCovered: 1, LinesTotal: 10},
+			{Path: "small.go", LinesCovered: 8, LinesTotal: 10, UncoveredLines: []int{6, 7}},
+			{Path: "same.go", LinesCovered: 5, LinesTotal: 10},
+			{Path: "large.go", LinesCovered: 8, LinesTotal: 10, UncoveredLines: []int{9, 10}},
+		},
+	}
+
+	comp := NewComparison(head, base, []string{"changed.go"})
+
+	if len(comp.FileChanges) != 1 || comp.FileChanges[0].Path != "changed.go" {
+		t.Fatalf("FileChanges = %+v, want only changed.go", comp.FileChanges)
+	}
+	if len(comp.IndirectChanges) != 2 {
+		t.Fatalf("IndirectChanges = %+v, want large.go and small.go", comp.IndirectChanges)
+	}
+	large, small := comp.IndirectChanges[0], comp.IndirectChanges[1]
+	if large.Path != "large.go" || math.Abs(large.Delta+60) > 1e-9 || large.NewlyUncovered != 6 {
+		t.Errorf("IndirectChanges[0] = %+v, want large.go with -60%% and 6 newly uncovered", large)
+	}
+	if small.Path != "small.go" || math.Abs(small.Delta-10) > 1e-9 || small.NewlyUncovered != -1 {
+		t.Errorf("IndirectChanges[1] = %+v, want small.go with +10%% and -1 newly uncovered", small)
+	}
+
+	if comp := NewComparison(head, base, nil); comp.IndirectChanges != nil {
+		t.Errorf("without changed files every file is a direct change, got %+v", comp.IndirectChanges)
+	}
+}
+
+func TestComparison_FindIndirectChanges(t *testing.T) {
+	head := &Report{
+		Files: []FileCoverage{
+			{Path: "changed.go", LinesCovered: 5, LinesTotal: 10},
+			{Path: "same.go", LinesCovered: 5, LinesTotal: 10},
+			{Path: "dropped.go", LinesCovered: 2, LinesTotal: 10},
+		},
+	}
+	base := &Report{
+		Files: []FileCoverage{
+			{Path: "changed.go", LinesCovered: 1, LinesTotal: 10},
+			{Path: "same.go", LinesCovered: 5, LinesTotal: 10},
+			{Path: "dropped.go", LinesCovered: 8, LinesTotal: 10},
+		},
+	}
+
+	// show-files: all lists every file, without filtering by changed files
+	comp := NewComparison(head, base, nil)
+	comp.FindIndirectChanges([]string{"changed.go"})
+
+	if len(comp.FileChanges) != 3 {
+		t.Errorf("FileChanges = %+v, want every file", comp.FileChanges)
+	}
+	if len(comp.IndirectChanges) != 1 || comp.IndirectChanges[0].Path != "dropped.go" {
+		t.Errorf("IndirectChanges = %+v, want dropped.go", comp.IndirectChanges)
+	}
+
+	comp.FindIndirectChanges(nil)
+	if comp.IndirectChanges != nil {
+		t.Errorf("without changed files there are no indirect changes, got %+v", comp.IndirectChanges)
+	}
+}
+
+func TestComparison_AddPatch(t *testing.T) {
+	comp := &Comparison{
+		FileChanges: []FileChange{
+			{Path: "github.com/o/r/pkg/a.go"},
+			{Path: "pkg/b.go"},
+		},
+	}
+	comp.AddPatch(&Patch{
+		Files: []PatchFile{
+			{Path: "pkg/a.go", UncoveredLines: []int{4, 5}},
+		},
+	})
+
+	if got := comp.FileChanges[0].UncoveredAdded; len(got) != 2 || got[0] != 4 || got[1] != 5 {
+		t.Errorf("UncoveredAdded = %v, want [4 5]", got)
+	}
+	if got := comp.FileChanges[1].UncoveredAdded; got != nil {
+		t.Errorf("files outside the patch should have no added lines, got %v", got)
+	}
+
+	comp.AddPatch(nil)
+}
+
 func TestNewComparison_MissingFiles(t *testing.T) {
 	head := &Report{
 		Files: []FileCoverage{
@@ -360,3 +536,13 @@ func TestNewComparison_MissingFiles_SkipsTestFiles(t *testing.T) {
 	}
 }
 
+func TestNewComparison_MissingFiles_SkipsDefaultExcludes(t *testing.T) {
+	head := &Report{}
+
+	changedFiles := []string{"internal/foo/a.go", "vendor/github.com/pkg/errors/errors.go", "api/v1/types.pb.go", "internal/test/service_mock.go"}
+	comp := NewComparison(head, nil, changedFiles)
+
+	if len(comp.FileChanges) != 1 || comp.FileChanges[0].Path != "internal/foo/a.go" {
+		t.Errorf("FileChanges = %+v, want only internal/foo/a.go", comp.FileChanges)
+	}
+}
@@ -0,0 +1,147 @@
+package coverage
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+
+	"github.com/manashmandal/litecov/internal/paths"
+)
+
+// exclusionRegex matches the inline exclusion markers ignore, ignore-start,
+// ignore-end and ignore-file, prefixed with "litecov:". Like coverage.py
+// pragmas, a marker must directly follow a comment token and end at a space
+// or the end of the comment, so mentions in prose or string literals are
+// not markers.
+var exclusionRegex = regexp.MustCompile(`(?://|#|/\*|\*|--|;|%|<!--)\s*litecov:ignore(-start|-end|-file)?(?:\s|\*/|-->|$)`)
+
+// commentOnlyRegex matches lines that hold nothing but a comment.
+var commentOnlyRegex = regexp.MustCompile(`^\s*(//|#|/\*|\*|--|;|%|<!--)`)
+
+// Exclusions holds the source lines excluded from coverage by inline markers.
+type Exclusions struct {
+	Lines map[int]bool
+	File  bool
+}
+
+// ParseExclusions scans source code for inline exclusion markers.
+// A trailing "litecov:ignore" excludes its own line; on a line of its own it
+// excludes the next line. "litecov:ignore-start" and "litecov:ignore-end"
+// exclude every line between them inclusive, and "litecov:ignore-file"
+// excludes the whole file.
+func ParseExclusions(r io.Reader) (Exclusions, error) {
+	ex := Exclusions{Lines: make(map[int]bool)}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	lineNum := 0
+	inBlock := false
+	ignoreNext := false
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+
+		if ignoreNext {
+			ex.Lines[lineNum] = true
+			ignoreNext = false
+		}
+		if inBlock {
+			ex.Lines[lineNum] = true
+		}
+
+		match := exclusionRegex.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		switch match[1] {
+		case "-file":
+			ex.File = true
+		case "-start":
+			inBlock = true
+			ex.Lines[lineNum] = true
+		case "-end":
+			inBlock = false
+			ex.Lines[lineNum] = true
+		default:
+			ex.Lines[lineNum] = true
+			if commentOnlyRegex.MatchString(line) {
+				ignoreNext = true
+			}
+		}
+	}
+	return ex, scanner.Err()
+}
+
+// Exclude removes the given lines from the file's coverage data and returns
+// how many measured lines were dropped.
+func (fc *FileCoverage) Exclude(lines map[int]bool) int {
+	excluded := 0
+
+	uncovered := fc.UncoveredLines[:0]
+	for _, l := range fc.UncoveredLines {
+		if lines[l] {
+			excluded++
+			fc.LinesTotal--
+			continue
+		}
+		uncovered = append(uncovered, l)
+	}
+	fc.UncoveredLines = uncovered
+
+	covered := fc.CoveredLines[:0]
+	for _, l := range fc.CoveredLines {
+		if lines[l] {
+			excluded++
+			fc.LinesTotal--
+			fc.LinesCovered--
+			continue
+		}
+		covered = append(covered, l)
+	}
+	fc.CoveredLines = covered
+
+	partial := fc.PartialLines[:0]
+	for _, l := range fc.PartialLines {
+		if !lines[l] {
+			partial = append(partial, l)
+		}
+	}
+	fc.PartialLines = partial
+	for l := range lines {
+		delete(fc.LineHits, l)
+	}
+
+	fc.ExcludedLines += excluded
+	return excluded
+}
+
+// ApplyExclusions reads each file's source from the checkout at root and
+// drops lines marked with inline exclusion markers. Files marked as ignored
+// as a whole are removed from the report. Files that cannot be read
+// are left unchanged. Totals are recalculated and the number of excluded
+// lines is recorded in ExcludedLines.
+func (r *Report) ApplyExclusions(root string) {
+	files := r.Files[:0]
+	for _, fc := range r.Files {
+		f, err := paths.OpenSource(root, fc.Path)
+		if err != nil {
+			files = append(files, fc)
+			continue
+		}
+		ex, err := ParseExclusions(f)
+		f.Close()
+		if err != nil {
+			files = append(files, fc)
+			continue
+		}
+
+		if ex.File {
+			r.ExcludedLines += fc.LinesTotal
+			continue
+		}
+		r.ExcludedLines += fc.Exclude(ex.Lines)
+		files = append(files, fc)
+	}
+	r.Files = files
+	r.Calculate()
+}
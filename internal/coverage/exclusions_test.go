@@ -0,0 +1,197 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func sortedLines(lines map[int]bool) []int {
+	var result []int
+	for l := range lines {
+		result = append(result, l)
+	}
+	sort.Ints(result)
+	return result
+}
+
+func TestParseExclusions(t *testing.T) {
+	src := `package foo
+
+func a() {
+	panic("x") // litecov:ignore
+}
+
+// litecov:ignore
+func b() {}
+
+// litecov:ignore-start
+func c() {
+	return
+}
+// litecov:ignore-end
+
+func d() {}
+`
+	ex, err := ParseExclusions(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("ParseExclusions() error = %v", err)
+	}
+	if ex.File {
+		t.Error("File should be false")
+	}
+	want := []int{4, 7, 8, 10, 11, 12, 13, 14}
+	if got := sortedLines(ex.Lines); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines = %v, want %v", got, want)
+	}
+}
+
+func TestParseExclusions_File(t *testing.T) {
+	src := "# litecov:ignore-file\nimport os\n"
+	ex, err := ParseExclusions(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("ParseExclusions() error = %v", err)
+	}
+	if !ex.File {
+		t.Error("File should be true")
+	}
+}
+
+func TestParseExclusions_PythonStyle(t *testing.T) {
+	src := "def f():\n    raise Exception()  # litecov:ignore\n"
+	ex, err := ParseExclusions(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("ParseExclusions() error = %v", err)
+	}
+	if want := []int{2}; !reflect.DeepEqual(sortedLines(ex.Lines), want) {
+		t.Errorf("Lines = %v, want %v", sortedLines(ex.Lines), want)
+	}
+}
+
+func TestParseExclusions_NotInComment(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"string literal", "package foo\n\nconst help = \"Honour litecov:ignore-file markers\"\nvar x = 1\n"},
+		{"prose", "package foo\n\n// Lines after a litecov:ignore-start marker are excluded.\nfunc a() {}\n"},
+		{"marker in a sentence", "package foo\n\n// litecov:ignore-file, litecov:ignore-start and litecov:ignore-end\nfunc a() {}\n"},
+		{"flag help", "package main\n\nvar f = flag.Bool(\"ignore-markers\", true, \"Honour litecov:ignore markers\")\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ex, err := ParseExclusions(strings.NewReader(tt.src))
+			if err != nil {
+				t.Fatalf("ParseExclusions() error = %v", err)
+			}
+			if ex.File || len(ex.Lines) != 0 {
+				t.Errorf("ParseExclusions() = %+v, want no exclusions", ex)
+			}
+		})
+	}
+}
+
+func TestParseExclusions_UnmatchedStart(t *testing.T) {
+	src := `package foo
+
+// Wrap code in litecov:ignore-start and litecov:ignore-end comments.
+func a() {}
+
+func b() {} // litecov:ignore
+`
+	ex, err := ParseExclusions(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("ParseExclusions() error = %v", err)
+	}
+	if want := []int{6}; !reflect.DeepEqual(sortedLines(ex.Lines), want) {
+		t.Errorf("Lines = %v, want %v", sortedLines(ex.Lines), want)
+	}
+}
+
+func TestParseExclusions_BlockComment(t *testing.T) {
+	src := "int f() {\n  abort(); /* litecov:ignore */\n}\n"
+	ex, err := ParseExclusions(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("ParseExclusions() error = %v", err)
+	}
+	if want := []int{2}; !reflect.DeepEqual(sortedLines(ex.Lines), want) {
+		t.Errorf("Lines = %v, want %v", sortedLines(ex.Lines), want)
+	}
+}
+
+func TestFileCoverage_Exclude(t *testing.T) {
+	fc := FileCoverage{
+		Path:           "a.go",
+		LinesCovered:   3,
+		LinesTotal:     5,
+		UncoveredLines: []int{2, 4},
+		CoveredLines:   []int{1, 3, 5},
+		LineHits:       map[int]int{1: 1, 2: 0, 3: 2, 4: 0, 5: 1},
+		PartialLines:   []int{3, 5},
+	}
+
+	excluded := fc.Exclude(map[int]bool{3: true, 4: true, 9: true})
+
+	if excluded != 2 {
+		t.Errorf("Exclude() = %v, want 2", excluded)
+	}
+	if fc.LinesTotal != 3 || fc.LinesCovered != 2 {
+		t.Errorf("LinesCovered/LinesTotal = %d/%d, want 2/3", fc.LinesCovered, fc.LinesTotal)
+	}
+	if !reflect.DeepEqual(fc.UncoveredLines, []int{2}) {
+		t.Errorf("UncoveredLines = %v, want [2]", fc.UncoveredLines)
+	}
+	if !reflect.DeepEqual(fc.CoveredLines, []int{1, 5}) {
+		t.Errorf("CoveredLines = %v, want [1 5]", fc.CoveredLines)
+	}
+	if fc.ExcludedLines != 2 {
+		t.Errorf("ExcludedLines = %v, want 2", fc.ExcludedLines)
+	}
+	if !reflect.DeepEqual(fc.PartialLines, []int{5}) {
+		t.Errorf("PartialLines = %v, want [5]", fc.PartialLines)
+	}
+	if _, ok := fc.LineHits[3]; ok || len(fc.LineHits) != 3 {
+		t.Errorf("LineHits = %v, excluded lines should be dropped", fc.LineHits)
+	}
+}
+
+func TestReport_ApplyExclusions(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("internal/a.go", "package a\n\nfunc f() {\n\tpanic(1) // litecov:ignore\n}\n")
+	write("internal/b.go", "// litecov:ignore-file\npackage b\n")
+
+	report := &Report{
+		Files: []FileCoverage{
+			{Path: "github.com/user/repo/internal/a.go", LinesCovered: 1, LinesTotal: 2, CoveredLines: []int{3}, UncoveredLines: []int{4}},
+			{Path: "internal/b.go", LinesCovered: 0, LinesTotal: 3, UncoveredLines: []int{2, 3, 4}},
+			{Path: "internal/missing.go", LinesCovered: 1, LinesTotal: 1, CoveredLines: []int{1}},
+		},
+	}
+	report.Calculate()
+	report.ApplyExclusions(root)
+
+	if len(report.Files) != 2 {
+		t.Fatalf("got %d files, want 2", len(report.Files))
+	}
+	if report.ExcludedLines != 4 {
+		t.Errorf("ExcludedLines = %v, want 4", report.ExcludedLines)
+	}
+	if report.TotalLines != 2 || report.TotalCovered != 2 {
+		t.Errorf("TotalCovered/TotalLines = %d/%d, want 2/2", report.TotalCovered, report.TotalLines)
+	}
+	if report.Coverage != 100 {
+		t.Errorf("Coverage = %v, want 100", report.Coverage)
+	}
+}
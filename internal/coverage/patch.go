@@ -0,0 +1,96 @@
+package coverage
+
+import (
+	"sort"
+
+	"github.com/manashmandal/litecov/internal/diff"
+	"github.com/manashmandal/litecov/internal/paths"
+)
+
+// Patch holds the coverage of the lines added in a change.
+type Patch struct {
+	Covered int
+	Total   int
+	Files   []PatchFile
+}
+
+// PatchFile holds the coverage of the lines added to a single file.
+type PatchFile struct {
+	// Path is the repo-relative path from the diff.
+	Path           string
+	Covered        int
+	Total          int
+	UncoveredLines []int
+}
+
+// Coverage returns the percentage of added coverable lines that are covered.
+func (p *Patch) Coverage() float64 {
+	if p == nil || p.Total == 0 {
+		return 0
+	}
+	return float64(p.Covered) / float64(p.Total) * 100
+}
+
+// Coverage returns the percentage of added coverable lines that are covered.
+func (pf *PatchFile) Coverage() float64 {
+	if pf.Total == 0 {
+		return 0
+	}
+	return float64(pf.Covered) / float64(pf.Total) * 100
+}
+
+// NewPatch computes patch coverage: only added lines that the report measured
+// count, so comments, blank lines and declarations are ignored.
+func NewPatch(report *Report, diffs []diff.FileDiff) *Patch {
+	patch := &Patch{}
+	if report == nil {
+		return patch
+	}
+
+	diffSet := make(map[string]bool)
+	diffByPath := make(map[string]diff.FileDiff)
+	for _, fd := range diffs {
+		diffSet[fd.Path] = true
+		diffByPath[fd.Path] = fd
+	}
+
+	for _, fc := range report.Files {
+		matched := paths.FindMatchingChangedFile(fc.Path, diffSet)
+		if matched == "" {
+			continue
+		}
+
+		covered := make(map[int]bool, len(fc.CoveredLines))
+		for _, l := range fc.CoveredLines {
+			covered[l] = true
+		}
+		uncovered := make(map[int]bool, len(fc.UncoveredLines))
+		for _, l := range fc.UncoveredLines {
+			uncovered[l] = true
+		}
+
+		pf := PatchFile{Path: matched}
+		for _, l := range diffByPath[matched].Lines() {
+			switch {
+			case covered[l]:
+				pf.Covered++
+				pf.Total++
+			case uncovered[l]:
+				pf.Total++
+				pf.UncoveredLines = append(pf.UncoveredLines, l)
+			}
+		}
+		if pf.Total == 0 {
+			continue
+		}
+
+		patch.Covered += pf.Covered
+		patch.Total += pf.Total
+		patch.Files = append(patch.Files, pf)
+	}
+
+	sort.Slice(patch.Files, func(i, j int) bool {
+		return patch.Files[i].Path < patch.Files[j].Path
+	})
+	return patch
+}
@@ -0,0 +1,64 @@
+package coverage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/manashmandal/litecov/internal/diff"
+)
+
+func TestNewPatch(t *testing.T) {
+	report := &Report{
+		Files: []FileCoverage{
+			{
+				Path:           "github.com/user/repo/internal/a.go",
+				CoveredLines:   []int{1, 2, 3, 10},
+				UncoveredLines: []int{4, 5, 11},
+			},
+			{
+				Path:         "internal/b.go",
+				CoveredLines: []int{1},
+			},
+			{
+				Path:           "internal/untouched.go",
+				UncoveredLines: []int{1, 2},
+			},
+		},
+	}
+	diffs := []diff.FileDiff{
+		{Path: "internal/a.go", AddedLines: []diff.LineRange{{Start: 2, End: 6}, {Start: 11, End: 11}}},
+		{Path: "internal/b.go", AddedLines: []diff.LineRange{{Start: 20, End: 25}}},
+		{Path: "README.md", AddedLines: []diff.LineRange{{Start: 1, End: 3}}},
+	}
+
+	patch := NewPatch(report, diffs)
+
+	if patch.Covered != 2 || patch.Total != 5 {
+		t.Errorf("Covered/Total = %d/%d, want 2/5", patch.Covered, patch.Total)
+	}
+	if patch.Coverage() != 40 {
+		t.Errorf("Coverage() = %v, want 40", patch.Coverage())
+	}
+	if len(patch.Files) != 1 {
+		t.Fatalf("got %d files, want 1 (files without coverable added lines are skipped)", len(patch.Files))
+	}
+	pf := patch.Files[0]
+	if pf.Path != "internal/a.go" {
+		t.Errorf("Path = %q, want internal/a.go", pf.Path)
+	}
+	if !reflect.DeepEqual(pf.UncoveredLines, []int{4, 5, 11}) {
+		t.Errorf("UncoveredLines = %v, want [4 5 11]", pf.UncoveredLines)
+	}
+}
+
+func TestNewPatch_Empty(t *testing.T) {
+	patch := NewPatch(nil, nil)
+	if patch.Total != 0 || patch.Coverage() != 0 {
+		t.Errorf("empty patch = %+v", patch)
+	}
+
+	var nilPatch *Patch
+	if nilPatch.Coverage() != 0 {
+		t.Error("nil patch coverage should be 0")
+	}
+}
@@ -0,0 +1,143 @@
+package coverage
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/manashmandal/litecov/internal/paths"
+)
+
+// Ratchet gate kinds.
+const (
+	// GateProjectDrop fails when project coverage drops by more than Limit points.
+	GateProjectDrop = "project-drop"
+	// GateFileDrop fails when a changed file's coverage drops by more than Limit points.
+	GateFileDrop = "file-drop"
+	// GateNewFile fails when a new file's coverage is below Limit percent.
+	GateNewFile = "new-file"
+)
+
+// ratchetEpsilon absorbs floating point noise when comparing deltas.
+const ratchetEpsilon = 1e-9
+
+// RatchetGate is a check of head coverage against the base report.
+type RatchetGate struct {
+	Kind  string
+	Limit float64
+}
+
+// GateResult is the outcome of a single ratchet gate.
+type GateResult struct {
+	Gate   RatchetGate
+	Passed bool
+	// Actual is the project delta, the worst file delta or the lowest new
+	// file coverage, depending on the gate kind.
+	Actual float64
+	// Files lists the files that failed the gate.
+	Files []string
+}
+
+// Description summarizes the gate result in a single line.
+func (r GateResult) Description() string {
+	switch r.Gate.Kind {
+	case GateProjectDrop:
+		return fmt.Sprintf("%+.2f%% vs base (max drop %.2f%%)", r.Actual, r.Gate.Limit)
+	case GateFileDrop:
+		if len(r.Files) == 0 {
+			return fmt.Sprintf("no file dropped more than %.2f%%", r.Gate.Limit)
+		}
+		return fmt.Sprintf("%d files dropped more than %.2f%%, worst %+.2f%%", len(r.Files), r.Gate.Limit, r.Actual)
+	case GateNewFile:
+		if len(r.Files) == 0 {
+			return fmt.Sprintf("all new files at least %.2f%%", r.Gate.Limit)
+		}
+		return fmt.Sprintf("%d new files below %.2f%%, lowest %.2f%%", len(r.Files), r.Gate.Limit, r.Actual)
+	default:
+		return r.Gate.Kind
+	}
+}
+
+// CheckRatchet evaluates the gates against the comparison. The file gates
+// look at changedFiles, the files changed by the pull request, whichever
+// files the comparison lists, and are skipped without them. filter decides
+// which changed files without coverage data count as untested. It returns
+// nil when there is no base report to compare with.
+func (c *Comparison) CheckRatchet(gates []RatchetGate, changedFiles []string, filter *paths.Filter) []GateResult {
+	if c == nil || c.Head == nil || c.Base == nil {
+		return nil
+	}
+
+	var changes []FileChange
+	if len(changedFiles) > 0 {
+		changes = NewComparisonWithFilter(c.Head, c.Base, changedFiles, filter).FileChanges
+	}
+
+	var results []GateResult
+	for _, gate := range gates {
+		limit := math.Abs(gate.Limit)
+		result := GateResult{Gate: RatchetGate{Kind: gate.Kind, Limit: limit}, Passed: true}
+
+		switch gate.Kind {
+		case GateProjectDrop:
+			result.Actual = c.CoverageDelta
+			result.Passed = c.CoverageDelta >= -limit-ratchetEpsilon
+
+		case GateFileDrop:
+			if len(changedFiles) == 0 {
+				continue
+			}
+			var failing []FileChange
+			for _, fc := range changes {
+				if fc.IsNew {
+					continue
+				}
+				if fc.Delta < result.Actual {
+					result.Actual = fc.Delta
+				}
+				if fc.Delta < -limit-ratchetEpsilon {
+					failing = append(failing, fc)
+				}
+			}
+			sort.SliceStable(failing, func(i, j int) bool { return failing[i].Delta < failing[j].Delta })
+			result.Files = changePaths(failing)
+			result.Passed = len(failing) == 0
+
+		case GateNewFile:
+			if len(changedFiles) == 0 {
+				continue
+			}
+			var failing []FileChange
+			result.Actual = 100
+			for _, fc := range changes {
+				if !fc.IsNew {
+					continue
+				}
+				if fc.HeadCoverage < result.Actual {
+					result.Actual = fc.HeadCoverage
+				}
+				if fc.HeadCoverage < limit-ratchetEpsilon {
+					failing = append(failing, fc)
+				}
+			}
+			sort.SliceStable(failing, func(i, j int) bool { return failing[i].HeadCoverage < failing[j].HeadCoverage })
+			result.Files = changePaths(failing)
+			result.Passed = len(failing) == 0
+
+		default:
+			continue
+		}
+
+		results = append(results, result)
+	}
+	return results
+}
+
+// changePaths returns the repo-relative paths of file changes.
+func changePaths(changes []FileChange) []string {
+	result := make([]string, len(changes))
+	for i, fc := range changes {
+		result[i] = paths.NormalizePathForAnnotation(fc.Path)
+	}
+	return result
+}
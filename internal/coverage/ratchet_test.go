@@ -0,0 +1,159 @@
+package coverage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func ratchetComparison() *Comparison {
+	base := &Report{
+		Files: []FileCoverage{
+			{Path: "internal/a.go", LinesCovered: 90, LinesTotal: 100},
+			{Path: "internal/b.go", LinesCovered: 80, LinesTotal: 100},
+		},
+	}
+	base.Calculate()
+	head := &Report{
+		Files: []FileCoverage{
+			{Path: "internal/a.go", LinesCovered: 89, LinesTotal: 100},
+			{Path: "internal/b.go", LinesCovered: 70, LinesTotal: 100},
+			{Path: "internal/c.go", LinesCovered: 40, LinesTotal: 100},
+		},
+	}
+	head.Calculate()
+	return NewComparison(head, base, nil)
+}
+
+// ratchetChanged are the files the pull request of ratchetComparison changed.
+var ratchetChanged = []string{"internal/a.go", "internal/b.go", "internal/c.go"}
+
+func TestCheckRatchet_ProjectDrop(t *testing.T) {
+	comp := ratchetComparison()
+
+	results := comp.CheckRatchet([]RatchetGate{{Kind: GateProjectDrop, Limit: -0.5}}, ratchetChanged, nil)
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	if results[0].Passed {
+		t.Errorf("project drop of %.2f should fail a 0.5 limit", results[0].Actual)
+	}
+	if results[0].Gate.Limit != 0.5 {
+		t.Errorf("Limit = %v, want 0.5 (absolute value)", results[0].Gate.Limit)
+	}
+
+	results = comp.CheckRatchet([]RatchetGate{{Kind: GateProjectDrop, Limit: 50}}, ratchetChanged, nil)
+	if !results[0].Passed {
+		t.Error("project drop should pass a 50 point limit")
+	}
+}
+
+func TestCheckRatchet_FileDrop(t *testing.T) {
+	comp := ratchetComparison()
+
+	results := comp.CheckRatchet([]RatchetGate{{Kind: GateFileDrop, Limit: 5}}, ratchetChanged, nil)
+	r := results[0]
+	if r.Passed {
+		t.Error("file drop gate should fail")
+	}
+	if !reflect.DeepEqual(r.Files, []string{"internal/b.go"}) {
+		t.Errorf("Files = %v, want [internal/b.go]", r.Files)
+	}
+	if r.Actual != -10 {
+		t.Errorf("Actual = %v, want -10", r.Actual)
+	}
+}
+
+func TestCheckRatchet_ExactLimitPasses(t *testing.T) {
+	comp := ratchetComparison()
+
+	results := comp.CheckRatchet([]RatchetGate{{Kind: GateFileDrop, Limit: 10}}, ratchetChanged, nil)
+	if !results[0].Passed {
+		t.Errorf("drop equal to the limit should pass: %+v", results[0])
+	}
+}
+
+func TestCheckRatchet_NewFile(t *testing.T) {
+	comp := ratchetComparison()
+
+	results := comp.CheckRatchet([]RatchetGate{{Kind: GateNewFile, Limit: 60}}, ratchetChanged, nil)
+	r := results[0]
+	if r.Passed {
+		t.Error("new file gate should fail")
+	}
+	if !reflect.DeepEqual(r.Files, []string{"internal/c.go"}) {
+		t.Errorf("Files = %v, want [internal/c.go]", r.Files)
+	}
+
+	results = comp.CheckRatchet([]RatchetGate{{Kind: GateNewFile, Limit: 40}}, ratchetChanged, nil)
+	if !results[0].Passed {
+		t.Error("new file at exactly the minimum should pass")
+	}
+}
+
+func TestCheckRatchet_ChangedFilesOnly(t *testing.T) {
+	// With show-files: all the comparison lists every file, but the file
+	// gates still only look at the files the pull request changed
+	comp := ratchetComparison()
+	gates := []RatchetGate{{Kind: GateFileDrop, Limit: 5}, {Kind: GateNewFile, Limit: 60}}
+
+	results := comp.CheckRatchet(gates, []string{"internal/a.go"}, nil)
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	for _, r := range results {
+		if !r.Passed {
+			t.Errorf("%s gate should ignore unchanged files: %+v", r.Gate.Kind, r)
+		}
+	}
+
+	results = comp.CheckRatchet(gates, []string{"internal/a.go", "internal/c.go"}, nil)
+	if !results[0].Passed || results[1].Passed {
+		t.Errorf("only the changed new file should fail: %+v", results)
+	}
+}
+
+func TestCheckRatchet_NoChangedFiles(t *testing.T) {
+	gates := []RatchetGate{{Kind: GateProjectDrop, Limit: 50}, {Kind: GateFileDrop, Limit: 5}, {Kind: GateNewFile, Limit: 60}}
+	results := ratchetComparison().CheckRatchet(gates, nil, nil)
+	if len(results) != 1 || results[0].Gate.Kind != GateProjectDrop {
+		t.Errorf("file gates should be skipped without changed files, got %+v", results)
+	}
+}
+
+func TestCheckRatchet_NoBase(t *testing.T) {
+	head := &Report{Files: []FileCoverage{{Path: "a.go", LinesCovered: 1, LinesTotal: 2}}}
+	head.Calculate()
+	comp := NewComparison(head, nil, nil)
+
+	if results := comp.CheckRatchet([]RatchetGate{{Kind: GateProjectDrop, Limit: 0}}, ratchetChanged, nil); results != nil {
+		t.Errorf("CheckRatchet() without base = %v, want nil", results)
+	}
+}
+
+func TestCheckRatchet_UnknownGate(t *testing.T) {
+	if results := ratchetComparison().CheckRatchet([]RatchetGate{{Kind: "bogus"}}, ratchetChanged, nil); len(results) != 0 {
+		t.Errorf("unknown gate should be ignored, got %v", results)
+	}
+}
+
+func TestGateResult_Description(t *testing.T) {
+	tests := []struct {
+		result   GateResult
+		contains string
+	}{
+		{GateResult{Gate: RatchetGate{Kind: GateProjectDrop, Limit: 0.5}, Actual: -1.25}, "-1.25% vs base (max drop 0.50%)"},
+		{GateResult{Gate: RatchetGate{Kind: GateFileDrop, Limit: 5}}, "no file dropped"},
+		{GateResult{Gate: RatchetGate{Kind: GateFileDrop, Limit: 5}, Actual: -10, Files: []string{"a.go"}}, "1 files dropped more than 5.00%, worst -10.00%"},
+		{GateResult{Gate: RatchetGate{Kind: GateNewFile, Limit: 60}}, "all new files at least 60.00%"},
+		{GateResult{Gate: RatchetGate{Kind: GateNewFile, Limit: 60}, Actual: 40, Files: []string{"c.go"}}, "1 new files below 60.00%, lowest 40.00%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contains, func(t *testing.T) {
+			if got := tt.result.Description(); !strings.Contains(got, tt.contains) {
+				t.Errorf("Description() = %q, want it to contain %q", got, tt.contains)
+			}
+		})
+	}
+}
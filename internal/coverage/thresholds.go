@@ -0,0 +1,146 @@
+package coverage
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/manashmandal/litecov/internal/paths"
+)
+
+// ThresholdRule requires the files matching Pattern to reach Minimum coverage
+// in aggregate, e.g. "internal/billing/**: 90".
+type ThresholdRule struct {
+	Pattern string
+	Minimum float64
+}
+
+// Thresholds holds the coverage gates checked against a report.
+type Thresholds struct {
+	// Project is the minimum coverage of the whole report.
+	Project float64
+	// File is the minimum coverage of any single file.
+	File float64
+	// Rules are minimums for groups of files selected by glob pattern.
+	Rules []ThresholdRule
+}
+
+// Violation describes a threshold that a report does not meet.
+type Violation struct {
+	// Rule is "project", "file" or the pattern of the failing ThresholdRule.
+	Rule     string
+	Coverage float64
+	Minimum  float64
+	// Files lists the offending files, lowest coverage first.
+	Files []string
+}
+
+// ParseThresholdRules parses comma or newline separated "pattern: minimum"
+// rules, e.g. "internal/billing/**: 90, cmd/**: 40".
+func ParseThresholdRules(s string) ([]ThresholdRule, error) {
+	var rules []ThresholdRule
+	for _, entry := range paths.SplitPatterns(s) {
+		idx := strings.LastIndex(entry, ":")
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid threshold rule %q: expected pattern: minimum", entry)
+		}
+		pattern := strings.TrimSpace(entry[:idx])
+		minimum, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(entry[idx+1:]), "%")), 64)
+		if err != nil || pattern == "" {
+			return nil, fmt.Errorf("invalid threshold rule %q: expected pattern: minimum", entry)
+		}
+		rules = append(rules, ThresholdRule{Pattern: pattern, Minimum: minimum})
+	}
+	return rules, nil
+}
+
+// Check returns the thresholds the report violates. Files without measured
+// lines are ignored by the file and rule checks.
+func (t Thresholds) Check(report *Report) []Violation {
+	if report == nil {
+		return nil
+	}
+
+	var violations []Violation
+
+	if t.Project > 0 && report.Coverage < t.Project {
+		violations = append(violations, Violation{
+			Rule:     "project",
+			Coverage: report.Coverage,
+			Minimum:  t.Project,
+		})
+	}
+
+	if t.File > 0 {
+		var failing []FileCoverage
+		for _, f := range report.Files {
+			if f.LinesTotal > 0 && f.Percentage() < t.File {
+				failing = append(failing, f)
+			}
+		}
+		if len(failing) > 0 {
+			lowest := lowestCoverage(failing)
+			violations = append(violations, Violation{
+				Rule:     "file",
+				Coverage: lowest[0].Percentage(),
+				Minimum:  t.File,
+				Files:    filePaths(lowest),
+			})
+		}
+	}
+
+	for _, rule := range t.Rules {
+		filter := &paths.Filter{Include: []string{rule.Pattern}}
+		var matched []FileCoverage
+		covered, total := 0, 0
+		for _, f := range report.Files {
+			if f.LinesTotal == 0 || !filter.Match(f.Path) {
+				continue
+			}
+			matched = append(matched, f)
+			covered += f.LinesCovered
+			total += f.LinesTotal
+		}
+		if total == 0 {
+			continue
+		}
+		pct := float64(covered) / float64(total) * 100
+		if pct >= rule.Minimum {
+			continue
+		}
+		var failing []FileCoverage
+		for _, f := range lowestCoverage(matched) {
+			if f.Percentage() < rule.Minimum {
+				failing = append(failing, f)
+			}
+		}
+		violations = append(violations, Violation{
+			Rule:     rule.Pattern,
+			Coverage: pct,
+			Minimum:  rule.Minimum,
+			Files:    filePaths(failing),
+		})
+	}
+
+	return violations
+}
+
+// lowestCoverage returns a copy of files sorted by ascending coverage.
+func lowestCoverage(files []FileCoverage) []FileCoverage {
+	sorted := make([]FileCoverage, len(files))
+	copy(sorted, files)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Percentage() < sorted[j].Percentage()
+	})
+	return sorted
+}
+
+// filePaths returns the repo-relative paths of files.
+func filePaths(files []FileCoverage) []string {
+	result := make([]string, len(files))
+	for i, f := range files {
+		result[i] = paths.NormalizePathForAnnotation(f.Path)
+	}
+	return result
+}
@@ -0,0 +1,121 @@
+package coverage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseThresholdRules(t *testing.T) {
+	rules, err := ParseThresholdRules("internal/billing/**: 90, cmd/**: 40%\n**/*.{go,py}:75.5")
+	if err != nil {
+		t.Fatalf("ParseThresholdRules() error = %v", err)
+	}
+	want := []ThresholdRule{
+		{Pattern: "internal/billing/**", Minimum: 90},
+		{Pattern: "cmd/**", Minimum: 40},
+		{Pattern: "**/*.{go,py}", Minimum: 75.5},
+	}
+	if !reflect.DeepEqual(rules, want) {
+		t.Errorf("ParseThresholdRules() = %v, want %v", rules, want)
+	}
+}
+
+func TestParseThresholdRules_Empty(t *testing.T) {
+	rules, err := ParseThresholdRules("")
+	if err != nil {
+		t.Fatalf("ParseThresholdRules() error = %v", err)
+	}
+	if len(rules) != 0 {
+		t.Errorf("got %d rules, want 0", len(rules))
+	}
+}
+
+func TestParseThresholdRules_Invalid(t *testing.T) {
+	for _, input := range []string{"internal/**", "internal/**: high", ": 50"} {
+		if _, err := ParseThresholdRules(input); err == nil {
+			t.Errorf("ParseThresholdRules(%q) expected error", input)
+		}
+	}
+}
+
+func thresholdReport() *Report {
+	report := &Report{
+		Files: []FileCoverage{
+			{Path: "github.com/user/repo/internal/billing/invoice.go", LinesCovered: 95, LinesTotal: 100},
+			{Path: "github.com/user/repo/internal/billing/tax.go", LinesCovered: 60, LinesTotal: 100},
+			{Path: "github.com/user/repo/cmd/app/main.go", LinesCovered: 20, LinesTotal: 100},
+			{Path: "github.com/user/repo/internal/empty.go", LinesCovered: 0, LinesTotal: 0},
+		},
+	}
+	report.Calculate()
+	return report
+}
+
+func TestThresholds_Check_Pass(t *testing.T) {
+	th := Thresholds{
+		Project: 50,
+		File:    10,
+		Rules:   []ThresholdRule{{Pattern: "cmd/**", Minimum: 20}},
+	}
+	if violations := th.Check(thresholdReport()); len(violations) != 0 {
+		t.Errorf("Check() = %v, want no violations", violations)
+	}
+}
+
+func TestThresholds_Check_Project(t *testing.T) {
+	th := Thresholds{Project: 80}
+	violations := th.Check(thresholdReport())
+	if len(violations) != 1 {
+		t.Fatalf("got %d violations, want 1", len(violations))
+	}
+	if violations[0].Rule != "project" || violations[0].Minimum != 80 {
+		t.Errorf("unexpected violation: %+v", violations[0])
+	}
+}
+
+func TestThresholds_Check_File(t *testing.T) {
+	th := Thresholds{File: 70}
+	violations := th.Check(thresholdReport())
+	if len(violations) != 1 {
+		t.Fatalf("got %d violations, want 1", len(violations))
+	}
+	want := []string{"cmd/app/main.go", "internal/billing/tax.go"}
+	if !reflect.DeepEqual(violations[0].Files, want) {
+		t.Errorf("Files = %v, want %v", violations[0].Files, want)
+	}
+	if violations[0].Coverage != 20 {
+		t.Errorf("Coverage = %v, want 20 (lowest file)", violations[0].Coverage)
+	}
+}
+
+func TestThresholds_Check_Rules(t *testing.T) {
+	th := Thresholds{
+		Rules: []ThresholdRule{
+			{Pattern: "internal/billing/**", Minimum: 90},
+			{Pattern: "cmd/**", Minimum: 40},
+			{Pattern: "pkg/**", Minimum: 99},
+		},
+	}
+	violations := th.Check(thresholdReport())
+	if len(violations) != 2 {
+		t.Fatalf("got %d violations, want 2: %+v", len(violations), violations)
+	}
+
+	billing := violations[0]
+	if billing.Rule != "internal/billing/**" || billing.Coverage != 77.5 {
+		t.Errorf("unexpected billing violation: %+v", billing)
+	}
+	if !reflect.DeepEqual(billing.Files, []string{"internal/billing/tax.go"}) {
+		t.Errorf("billing Files = %v, want [internal/billing/tax.go]", billing.Files)
+	}
+
+	if violations[1].Rule != "cmd/**" {
+		t.Errorf("unexpected cmd violation: %+v", violations[1])
+	}
+}
+
+func TestThresholds_Check_NilReport(t *testing.T) {
+	if violations := (Thresholds{Project: 80}).Check(nil); violations != nil {
+		t.Errorf("Check(nil) = %v, want nil", violations)
+	}
+}
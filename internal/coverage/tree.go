@@ -0,0 +1,169 @@
+package coverage
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// Orders accepted by SortDirs.
+const (
+	SortByName     = "name"
+	SortByCoverage = "coverage"
+	SortByChange   = "change"
+)
+
+// Dir is a directory of a coverage tree. Its totals roll up every file
+// below it.
+type Dir struct {
+	// Name is the last path element, and Path the slash separated path from
+	// the root. Both are empty for the root.
+	Name    string
+	Path    string
+	Covered int
+	Total   int
+	// BaseCovered and BaseTotal roll up the base versions of the files, when
+	// the tree was built with a base.
+	BaseCovered int
+	BaseTotal   int
+	Dirs        []*Dir
+	Files       []FileCoverage
+
+	// own holds the totals of the files directly in the directory.
+	own dirTotals
+}
+
+type dirTotals struct {
+	covered, total, baseCovered, baseTotal int
+}
+
+// Coverage returns the percentage of covered lines below the directory.
+func (d *Dir) Coverage() float64 {
+	if d.Total == 0 {
+		return 0
+	}
+	return float64(d.Covered) / float64(d.Total) * 100
+}
+
+// Delta returns the change in coverage from base, or false when the base
+// has no lines in the directory.
+func (d *Dir) Delta() (float64, bool) {
+	if d.BaseTotal == 0 {
+		return 0, false
+	}
+	return d.Coverage() - float64(d.BaseCovered)/float64(d.BaseTotal)*100, true
+}
+
+// NewTree arranges files by directory. Subdirectories and files are sorted
+// by name.
+func NewTree(files []FileCoverage) *Dir {
+	return NewTreeWithBase(files, nil)
+}
+
+// NewTreeWithBase arranges files by directory like NewTree and adds the
+// totals of the base files with the same paths, so that each directory can
+// report its change in coverage. Base files not in files are ignored.
+func NewTreeWithBase(files, base []FileCoverage) *Dir {
+	baseFiles := make(map[string]*FileCoverage, len(base))
+	for i := range base {
+		baseFiles[base[i].Path] = &base[i]
+	}
+
+	root := &Dir{}
+	for _, f := range files {
+		t := dirTotals{covered: f.LinesCovered, total: f.LinesTotal}
+		if b, ok := baseFiles[f.Path]; ok {
+			t.baseCovered, t.baseTotal = b.LinesCovered, b.LinesTotal
+		}
+
+		dir := root
+		dir.add(t)
+		parts := strings.Split(strings.Trim(path.Clean(f.Path), "/"), "/")
+		for _, name := range parts[:len(parts)-1] {
+			dir = dir.child(name)
+			dir.add(t)
+		}
+		dir.Files = append(dir.Files, f)
+		dir.own.add(t)
+	}
+	root.sort()
+	return root
+}
+
+func (t *dirTotals) add(o dirTotals) {
+	t.covered += o.covered
+	t.total += o.total
+	t.baseCovered += o.baseCovered
+	t.baseTotal += o.baseTotal
+}
+
+func (d *Dir) add(t dirTotals) {
+	d.Covered += t.covered
+	d.Total += t.total
+	d.BaseCovered += t.baseCovered
+	d.BaseTotal += t.baseTotal
+}
+
+func (d *Dir) child(name string) *Dir {
+	for _, c := range d.Dirs {
+		if c.Name == name {
+			return c
+		}
+	}
+	c := &Dir{Name: name, Path: path.Join(d.Path, name)}
+	d.Dirs = append(d.Dirs, c)
+	return c
+}
+
+func (d *Dir) sort() {
+	sort.Slice(d.Dirs, func(i, j int) bool { return d.Dirs[i].Name < d.Dirs[j].Name })
+	sort.Slice(d.Files, func(i, j int) bool { return d.Files[i].Path < d.Files[j].Path })
+	for _, c := range d.Dirs {
+		c.sort()
+	}
+}
+
+// Rollup flattens the tree to directories depth levels below d, each with
+// the totals of everything beneath it. Files in shallower directories are
+// rolled up into an entry for their own directory, without subdirectories.
+// The result is ordered by path.
+func (d *Dir) Rollup(depth int) []*Dir {
+	var dirs []*Dir
+	d.rollup(depth, &dirs)
+	return dirs
+}
+
+func (d *Dir) rollup(depth int, dirs *[]*Dir) {
+	if depth <= 0 || len(d.Dirs) == 0 {
+		*dirs = append(*dirs, d)
+		return
+	}
+	if len(d.Files) > 0 {
+		own := &Dir{Name: d.Name, Path: d.Path, Files: d.Files, own: d.own}
+		own.add(d.own)
+		*dirs = append(*dirs, own)
+	}
+	for _, c := range d.Dirs {
+		c.rollup(depth-1, dirs)
+	}
+}
+
+// SortDirs orders dirs by SortByCoverage (lowest first), SortByChange
+// (largest drop first, directories without a base last) or SortByName.
+func SortDirs(dirs []*Dir, by string) {
+	switch by {
+	case SortByCoverage:
+		sort.SliceStable(dirs, func(i, j int) bool { return dirs[i].Coverage() < dirs[j].Coverage() })
+	case SortByChange:
+		sort.SliceStable(dirs, func(i, j int) bool {
+			di, okI := dirs[i].Delta()
+			dj, okJ := dirs[j].Delta()
+			if okI != okJ {
+				return okI
+			}
+			return di < dj
+		})
+	default:
+		sort.SliceStable(dirs, func(i, j int) bool { return dirs[i].Path < dirs[j].Path })
+	}
+}
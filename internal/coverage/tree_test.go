@@ -0,0 +1,143 @@
+package coverage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewTree(t *testing.T) {
+	root := NewTree([]FileCoverage{
+		{Path: "internal/parser/lcov.go", LinesCovered: 8, LinesTotal: 10},
+		{Path: "main.go", LinesCovered: 0, LinesTotal: 5},
+		{Path: "internal/comment/comment.go", LinesCovered: 3, LinesTotal: 5},
+		{Path: "internal/parser/cobertura.go", LinesCovered: 2, LinesTotal: 10},
+	})
+
+	if root.Covered != 13 || root.Total != 30 {
+		t.Errorf("root = %d/%d, want 13/30", root.Covered, root.Total)
+	}
+	if len(root.Files) != 1 || root.Files[0].Path != "main.go" {
+		t.Errorf("root files = %v, want [main.go]", root.Files)
+	}
+	if len(root.Dirs) != 1 || root.Dirs[0].Path != "internal" {
+		t.Fatalf("root dirs = %v, want [internal]", root.Dirs)
+	}
+
+	internal := root.Dirs[0]
+	if internal.Covered != 13 || internal.Total != 25 {
+		t.Errorf("internal = %d/%d, want 13/25", internal.Covered, internal.Total)
+	}
+	if len(internal.Dirs) != 2 || internal.Dirs[0].Name != "comment" || internal.Dirs[1].Name != "parser" {
+		t.Fatalf("internal dirs should be sorted by name: %v", internal.Dirs)
+	}
+
+	parser := internal.Dirs[1]
+	if parser.Path != "internal/parser" || parser.Coverage() != 50 {
+		t.Errorf("parser = %s %.2f%%, want internal/parser 50%%", parser.Path, parser.Coverage())
+	}
+	if parser.Files[0].Path != "internal/parser/cobertura.go" {
+		t.Errorf("files should be sorted by path: %v", parser.Files)
+	}
+}
+
+func TestNewTree_Empty(t *testing.T) {
+	root := NewTree(nil)
+	if root.Total != 0 || root.Coverage() != 0 || len(root.Dirs) != 0 {
+		t.Errorf("empty tree = %+v", root)
+	}
+}
+
+func rollupPaths(dirs []*Dir) []string {
+	var paths []string
+	for _, d := range dirs {
+		paths = append(paths, d.Path)
+	}
+	return paths
+}
+
+func TestNewTreeWithBase(t *testing.T) {
+	head := []FileCoverage{
+		{Path: "a/x.go", LinesCovered: 9, LinesTotal: 10},
+		{Path: "a/new.go", LinesCovered: 5, LinesTotal: 10},
+		{Path: "b/y.go", LinesCovered: 2, LinesTotal: 10},
+	}
+	base := []FileCoverage{
+		{Path: "a/x.go", LinesCovered: 5, LinesTotal: 10},
+		{Path: "b/y.go", LinesCovered: 4, LinesTotal: 10},
+		{Path: "c/unchanged.go", LinesCovered: 1, LinesTotal: 10},
+	}
+
+	root := NewTreeWithBase(head, base)
+	if root.BaseCovered != 9 || root.BaseTotal != 20 {
+		t.Errorf("root base = %d/%d, want 9/20 (files outside head ignored)", root.BaseCovered, root.BaseTotal)
+	}
+
+	a := root.Dirs[0]
+	delta, ok := a.Delta()
+	if !ok || delta != 20 {
+		t.Errorf("a delta = %v, %v, want 20 (70%% vs 50%%)", delta, ok)
+	}
+
+	onlyNew := NewTreeWithBase([]FileCoverage{{Path: "n/new.go", LinesTotal: 4}}, base)
+	if _, ok := onlyNew.Dirs[0].Delta(); ok {
+		t.Error("a directory of new files should have no delta")
+	}
+}
+
+func TestDir_Rollup(t *testing.T) {
+	root := NewTree([]FileCoverage{
+		{Path: "main.go", LinesCovered: 1, LinesTotal: 2},
+		{Path: "internal/doc.go", LinesCovered: 1, LinesTotal: 1},
+		{Path: "internal/parser/lcov.go", LinesCovered: 8, LinesTotal: 10},
+		{Path: "internal/parser/xml/cobertura.go", LinesCovered: 2, LinesTotal: 10},
+		{Path: "internal/comment/comment.go", LinesCovered: 3, LinesTotal: 5},
+	})
+
+	tests := []struct {
+		depth int
+		want  []string
+	}{
+		{0, []string{""}},
+		{1, []string{"", "internal"}},
+		{2, []string{"", "internal", "internal/comment", "internal/parser"}},
+		{5, []string{"", "internal", "internal/comment", "internal/parser", "internal/parser/xml"}},
+	}
+
+	for _, tt := range tests {
+		got := rollupPaths(root.Rollup(tt.depth))
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("Rollup(%d) = %q, want %q", tt.depth, got, tt.want)
+		}
+	}
+
+	dirs := root.Rollup(2)
+	if dirs[0].Total != 2 || dirs[1].Total != 1 {
+		t.Errorf("shallow entries should only count their own files: root %d, internal %d", dirs[0].Total, dirs[1].Total)
+	}
+	if dirs[3].Covered != 10 || dirs[3].Total != 20 {
+		t.Errorf("internal/parser = %d/%d, want 10/20", dirs[3].Covered, dirs[3].Total)
+	}
+}
+
+func TestSortDirs(t *testing.T) {
+	dirs := []*Dir{
+		{Path: "a", Covered: 5, Total: 10, BaseCovered: 4, BaseTotal: 10},
+		{Path: "b", Covered: 9, Total: 10},
+		{Path: "c", Covered: 2, Total: 10, BaseCovered: 6, BaseTotal: 10},
+	}
+
+	SortDirs(dirs, SortByCoverage)
+	if got := rollupPaths(dirs); strings.Join(got, ",") != "c,a,b" {
+		t.Errorf("by coverage = %v, want c,a,b", got)
+	}
+
+	SortDirs(dirs, SortByChange)
+	if got := rollupPaths(dirs); strings.Join(got, ",") != "c,a,b" {
+		t.Errorf("by change = %v, want c,a,b (no base last)", got)
+	}
+
+	SortDirs(dirs, SortByName)
+	if got := rollupPaths(dirs); strings.Join(got, ",") != "a,b,c" {
+		t.Errorf("by name = %v, want a,b,c", got)
+	}
+}
@@ -96,3 +96,96 @@ func ParseUnifiedDiff(diffOutput string) []FileDiff {
 
 	return result
 }
+
+// ParseAddedLines parses unified diff output and returns the exact line numbers
+// added in each file. Unlike ParseUnifiedDiff, which takes ranges from the hunk
+// headers of `git diff --unified=0` output, it follows the hunk bodies, so it
+// also works with diffs that contain context lines, such as the GitHub API diff.
+func ParseAddedLines(diffOutput string) []FileDiff {
+	if diffOutput == "" {
+		return nil
+	}
+
+	var result []FileDiff
+	var currentFile *FileDiff
+	var isBinary bool
+	var inHunk bool
+	newLine := 0
+
+	appendLine := func(n int) {
+		ranges := currentFile.AddedLines
+		if len(ranges) > 0 && ranges[len(ranges)-1].End == n-1 {
+			ranges[len(ranges)-1].End = n
+			return
+		}
+		currentFile.AddedLines = append(ranges, LineRange{Start: n, End: n})
+	}
+
+	for _, line := range strings.Split(diffOutput, "\n") {
+		if matches := diffHeaderRegex.FindStringSubmatch(line); matches != nil {
+			if currentFile != nil && len(currentFile.AddedLines) > 0 {
+				result = append(result, *currentFile)
+			}
+			currentFile = &FileDiff{
+				Path:       matches[1],
+				AddedLines: []LineRange{},
+			}
+			isBinary = false
+			inHunk = false
+			continue
+		}
+
+		if currentFile == nil || isBinary {
+			continue
+		}
+
+		if binaryFileRegex.MatchString(line) {
+			isBinary = true
+			continue
+		}
+
+		if matches := hunkHeaderRegex.FindStringSubmatch(line); matches != nil {
+			start, err := strconv.Atoi(matches[1])
+			if err != nil {
+				inHunk = false
+				continue
+			}
+			newLine = start
+			inHunk = true
+			continue
+		}
+
+		if !inHunk {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "+"):
+			appendLine(newLine)
+			newLine++
+		case strings.HasPrefix(line, " "):
+			newLine++
+		case strings.HasPrefix(line, "-"), strings.HasPrefix(line, "\\"):
+			// Removed lines and "\ No newline at end of file" do not advance
+		default:
+			inHunk = false
+		}
+	}
+
+	if currentFile != nil && len(currentFile.AddedLines) > 0 {
+		result = append(result, *currentFile)
+	}
+
+	return result
+}
+
+// Lines returns every line number covered by the file's added ranges.
+func (fd FileDiff) Lines() []int {
+	var lines []int
+	for _, r := range fd.AddedLines {
+		for l := r.Start; l <= r.End; l++ {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
@@ -339,3 +339,97 @@ func TestFileDiff(t *testing.T) {
 		t.Errorf("expected 2 AddedLines, got %d", len(fd.AddedLines))
 	}
 }
+
+func TestParseAddedLines(t *testing.T) {
+	input := `diff --git a/file.go b/file.go
+index 1111111..2222222 100644
+--- a/file.go
++++ b/file.go
+@@ -10,6 +10,8 @@ func foo() {
+ context 10
+ context 11
+-removed
++added 12
++added 13
+ context 14
++added 15
+ context 16
+@@ -40,3 +42,4 @@ func bar() {
+ context 42
++added 43
+ context 44
+\ No newline at end of file
+diff --git a/image.png b/image.png
+Binary files a/image.png and b/image.png differ
+diff --git a/deleted.go b/deleted.go
+deleted file mode 100644
+--- a/deleted.go
++++ /dev/null
+@@ -1,2 +0,0 @@
+-package main
+-
+diff --git a/new.go b/new.go
+new file mode 100644
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1,3 @@
++package main
++
++func main() {}`
+
+	expected := []FileDiff{
+		{
+			Path: "file.go",
+			AddedLines: []LineRange{
+				{Start: 12, End: 13},
+				{Start: 15, End: 15},
+				{Start: 43, End: 43},
+			},
+		},
+		{
+			Path: "new.go",
+			AddedLines: []LineRange{
+				{Start: 1, End: 3},
+			},
+		},
+	}
+
+	result := ParseAddedLines(input)
+	if len(result) != len(expected) {
+		t.Fatalf("got %d files, want %d: %+v", len(result), len(expected), result)
+	}
+	for i := range expected {
+		if result[i].Path != expected[i].Path {
+			t.Errorf("file %d: path = %q, want %q", i, result[i].Path, expected[i].Path)
+		}
+		if len(result[i].AddedLines) != len(expected[i].AddedLines) {
+			t.Errorf("file %d: got %v, want %v", i, result[i].AddedLines, expected[i].AddedLines)
+			continue
+		}
+		for j := range expected[i].AddedLines {
+			if result[i].AddedLines[j] != expected[i].AddedLines[j] {
+				t.Errorf("file %d range %d: got %v, want %v", i, j, result[i].AddedLines[j], expected[i].AddedLines[j])
+			}
+		}
+	}
+}
+
+func TestParseAddedLines_Empty(t *testing.T) {
+	if result := ParseAddedLines(""); result != nil {
+		t.Errorf("ParseAddedLines(\"\") = %v, want nil", result)
+	}
+}
+
+func TestFileDiff_Lines(t *testing.T) {
+	fd := FileDiff{AddedLines: []LineRange{{Start: 3, End: 5}, {Start: 9, End: 9}}}
+	want := []int{3, 4, 5, 9}
+	got := fd.Lines()
+	if len(got) != len(want) {
+		t.Fatalf("Lines() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Lines() = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// jwtLifetime is how long an app JWT is valid; GitHub allows at most ten
+	// minutes.
+	jwtLifetime = 9 * time.Minute
+	// jwtClockSkew backdates the JWT issue time to tolerate clock drift.
+	jwtClockSkew = time.Minute
+	// tokenRefreshMargin refreshes installation tokens this long before they
+	// expire, so a token never runs out during a request.
+	tokenRefreshMargin = 5 * time.Minute
+)
+
+// TokenSource supplies the bearer token for API requests.
+type TokenSource interface {
+	Token() (string, error)
+}
+
+// AppAuth authenticates as a GitHub App installation. It signs a JWT with
+// the app's private key, exchanges it for an installation token and refreshes
+// the token before it expires.
+type AppAuth struct {
+	AppID int64
+	// InstallationID is looked up from the client's repository when zero.
+	InstallationID int64
+
+	client *Client
+	key    *rsa.PrivateKey
+	now    func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewAppAuth creates app authentication for requests made by client. The
+// client's BaseURL, HTTPClient and repository are used to obtain tokens.
+func NewAppAuth(client *Client, appID, installationID int64, privateKeyPEM []byte) (*AppAuth, error) {
+	key, err := ParsePrivateKey(privateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &AppAuth{
+		AppID:          appID,
+		InstallationID: installationID,
+		client:         client,
+		key:            key,
+		now:            time.Now,
+	}, nil
+}
+
+// ParsePrivateKey parses a PEM encoded RSA private key in PKCS#1 or PKCS#8
+// form, as downloaded from the GitHub App settings.
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found in private key")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// JWT returns a token signed with RS256 that authenticates as the app itself.
+func (a *AppAuth) JWT() (string, error) {
+	now := a.now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-jwtClockSkew).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": fmt.Sprint(a.AppID),
+	})
+
+	enc := base64.RawURLEncoding
+	signingInput := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + enc.EncodeToString(signature), nil
+}
+
+// Token returns a valid installation token, requesting a new one when the
+// cached token is missing or about to expire.
+func (a *AppAuth) Token() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token != "" && a.now().Add(tokenRefreshMargin).Before(a.expires) {
+		return a.token, nil
+	}
+
+	jwt, err := a.JWT()
+	if err != nil {
+		return "", err
+	}
+	if a.InstallationID == 0 {
+		if a.InstallationID, err = a.findInstallation(jwt); err != nil {
+			return "", err
+		}
+	}
+
+	path := fmt.Sprintf("/app/installations/%d/access_tokens", a.InstallationID)
+	resp, err := a.client.doRequestToken("POST", path, "application/vnd.github.v3+json", jwt, nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("GitHub API error: %s - %s", resp.Status, string(body))
+	}
+
+	var created struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", err
+	}
+	a.token = created.Token
+	a.expires = created.ExpiresAt
+	return a.token, nil
+}
+
+// findInstallation looks up the app installation on the client's repository.
+func (a *AppAuth) findInstallation(jwt string) (int64, error) {
+	path := fmt.Sprintf("/repos/%s/%s/installation", a.client.Owner, a.client.Repo)
+	resp, err := a.client.doRequestToken("GET", path, "application/vnd.github.v3+json", jwt, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("GitHub API error: %s - %s", resp.Status, string(body))
+	}
+
+	var installation struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&installation); err != nil {
+		return 0, err
+	}
+	return installation.ID, nil
+}
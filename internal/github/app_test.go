@@ -0,0 +1,193 @@
+package github
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key, pkcs1 := testKey(t)
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8 := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	for name, data := range map[string][]byte{"pkcs1": pkcs1, "pkcs8": pkcs8} {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := ParsePrivateKey(data)
+			if err != nil {
+				t.Fatalf("ParsePrivateKey() error = %v", err)
+			}
+			if !parsed.Equal(key) {
+				t.Error("parsed key does not match")
+			}
+		})
+	}
+}
+
+func TestParsePrivateKey_Invalid(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(ecKey)
+
+	tests := map[string][]byte{
+		"not pem": []byte("not a key"),
+		"garbage": pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: []byte("garbage")}),
+		"ecdsa":   pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParsePrivateKey(data); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestAppAuth_JWT(t *testing.T) {
+	key, keyPEM := testKey(t)
+	auth, err := NewAppAuth(&Client{}, 12345, 0, keyPEM)
+	if err != nil {
+		t.Fatalf("NewAppAuth() error = %v", err)
+	}
+	now := time.Unix(1700000000, 0)
+	auth.now = func() time.Time { return now }
+
+	jwt, err := auth.JWT()
+	if err != nil {
+		t.Fatalf("JWT() error = %v", err)
+	}
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("JWT has %d parts, want 3", len(parts))
+	}
+
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("invalid signature: %v", err)
+	}
+
+	var header map[string]string
+	data, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	json.Unmarshal(data, &header)
+	if header["alg"] != "RS256" || header["typ"] != "JWT" {
+		t.Errorf("unexpected header: %v", header)
+	}
+
+	var claims struct {
+		IAT int64  `json:"iat"`
+		EXP int64  `json:"exp"`
+		ISS string `json:"iss"`
+	}
+	data, _ = base64.RawURLEncoding.DecodeString(parts[1])
+	json.Unmarshal(data, &claims)
+	if claims.ISS != "12345" {
+		t.Errorf("iss = %v, want 12345", claims.ISS)
+	}
+	if claims.IAT != now.Unix()-60 || claims.EXP != now.Unix()+540 {
+		t.Errorf("iat = %d, exp = %d", claims.IAT, claims.EXP)
+	}
+}
+
+func TestAppAuth_Token(t *testing.T) {
+	_, keyPEM := testKey(t)
+	now := time.Now()
+	tokenRequests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/repos/owner/repo/installation":
+			if strings.Count(auth, ".") != 2 {
+				t.Errorf("installation lookup should use the app JWT, got %q", auth)
+			}
+			w.Write([]byte(`{"id": 7}`))
+		case "/app/installations/7/access_tokens":
+			if r.Method != "POST" || strings.Count(auth, ".") != 2 {
+				t.Errorf("unexpected token request: %s %q", r.Method, auth)
+			}
+			tokenRequests++
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"token": "ghs_%d", "expires_at": %q}`, tokenRequests, now.Add(time.Hour).Format(time.RFC3339))
+		case "/repos/owner/repo/pulls/1/files":
+			if auth != fmt.Sprintf("Bearer ghs_%d", tokenRequests) {
+				t.Errorf("request should use the installation token, got %q", auth)
+			}
+			w.Write([]byte(`[]`))
+		default:
+			t.Errorf("unexpected request: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{Owner: "owner", Repo: "repo", BaseURL: server.URL}
+	auth, err := NewAppAuth(client, 1, 0, keyPEM)
+	if err != nil {
+		t.Fatalf("NewAppAuth() error = %v", err)
+	}
+	auth.now = func() time.Time { return now }
+	client.Auth = auth
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetChangedFiles(1); err != nil {
+			t.Fatalf("GetChangedFiles() error = %v", err)
+		}
+	}
+	if tokenRequests != 1 {
+		t.Errorf("got %d token requests, want 1 while the token is valid", tokenRequests)
+	}
+	if auth.InstallationID != 7 {
+		t.Errorf("InstallationID = %d, want 7", auth.InstallationID)
+	}
+
+	// Within the refresh margin of expiry a new token is requested
+	now = now.Add(56 * time.Minute)
+	if _, err := client.GetChangedFiles(1); err != nil {
+		t.Fatalf("GetChangedFiles() error = %v", err)
+	}
+	if tokenRequests != 2 {
+		t.Errorf("got %d token requests, want 2 after refresh", tokenRequests)
+	}
+}
+
+func TestAppAuth_Token_Error(t *testing.T) {
+	_, keyPEM := testKey(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message": "Bad credentials"}`))
+	}))
+	defer server.Close()
+
+	client := &Client{Owner: "o", Repo: "r", BaseURL: server.URL}
+	auth, _ := NewAppAuth(client, 1, 3, keyPEM)
+	client.Auth = auth
+
+	if _, err := client.GetChangedFiles(1); err == nil || !strings.Contains(err.Error(), "failed to get token") {
+		t.Errorf("expected token error, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// MaxAnnotationsPerRequest is the number of annotations the Checks API
+// accepts in a single create or update request.
+const MaxAnnotationsPerRequest = 50
+
+// MaxCheckSummary is the longest check run summary the Checks API accepts,
+// in characters.
+const MaxCheckSummary = 65535
+
+// Check run conclusions.
+const (
+	ConclusionSuccess = "success"
+	ConclusionFailure = "failure"
+	ConclusionNeutral = "neutral"
+)
+
+// Annotation levels.
+const (
+	AnnotationNotice  = "notice"
+	AnnotationWarning = "warning"
+	AnnotationFailure = "failure"
+)
+
+// CheckRun describes a completed check run.
+type CheckRun struct {
+	Name       string
+	HeadSHA    string
+	Conclusion string
+	Title      string
+	// Summary is the markdown shown on the check run page.
+	Summary string
+}
+
+// CheckAnnotation marks a range of lines in a file of the check run.
+type CheckAnnotation struct {
+	Path            string `json:"path"`
+	StartLine       int    `json:"start_line"`
+	EndLine         int    `json:"end_line"`
+	AnnotationLevel string `json:"annotation_level"`
+	Title           string `json:"title,omitempty"`
+	Message         string `json:"message"`
+}
+
+type checkRunOutput struct {
+	Title       string            `json:"title"`
+	Summary     string            `json:"summary"`
+	Annotations []CheckAnnotation `json:"annotations,omitempty"`
+}
+
+// CreateCheckRun creates a completed check run and returns its ID. The first
+// batch of annotations is sent with the run and the rest are appended with
+// update requests, MaxAnnotationsPerRequest at a time.
+func (c *Client) CreateCheckRun(run CheckRun, annotations []CheckAnnotation) (int64, error) {
+	batches := batchAnnotations(annotations)
+	var first []CheckAnnotation
+	if len(batches) > 0 {
+		first = batches[0]
+		batches = batches[1:]
+	}
+
+	path := fmt.Sprintf("/repos/%s/%s/check-runs", c.Owner, c.Repo)
+	payload, _ := json.Marshal(map[string]interface{}{
+		"name":       run.Name,
+		"head_sha":   run.HeadSHA,
+		"status":     "completed",
+		"conclusion": run.Conclusion,
+		"output":     checkRunOutput{Title: run.Title, Summary: run.Summary, Annotations: first},
+	})
+
+	resp, err := c.doRequest("POST", path, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("GitHub API error: %s - %s", resp.Status, string(respBody))
+	}
+
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return 0, err
+	}
+
+	for _, batch := range batches {
+		if err := c.addCheckRunAnnotations(created.ID, run, batch); err != nil {
+			return created.ID, err
+		}
+	}
+	return created.ID, nil
+}
+
+// addCheckRunAnnotations appends annotations to an existing check run. The
+// output title and summary are required on every update.
+func (c *Client) addCheckRunAnnotations(id int64, run CheckRun, annotations []CheckAnnotation) error {
+	path := fmt.Sprintf("/repos/%s/%s/check-runs/%d", c.Owner, c.Repo, id)
+	payload, _ := json.Marshal(map[string]interface{}{
+		"output": checkRunOutput{Title: run.Title, Summary: run.Summary, Annotations: annotations},
+	})
+
+	resp, err := c.doRequest("PATCH", path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GitHub API error: %s - %s", resp.Status, string(respBody))
+	}
+	return nil
+}
+
+// batchAnnotations splits annotations into request sized batches.
+func batchAnnotations(annotations []CheckAnnotation) [][]CheckAnnotation {
+	var batches [][]CheckAnnotation
+	for len(annotations) > MaxAnnotationsPerRequest {
+		batches = append(batches, annotations[:MaxAnnotationsPerRequest])
+		annotations = annotations[MaxAnnotationsPerRequest:]
+	}
+	if len(annotations) > 0 {
+		batches = append(batches, annotations)
+	}
+	return batches
+}
@@ -0,0 +1,136 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testAnnotations(n int) []CheckAnnotation {
+	annotations := make([]CheckAnnotation, n)
+	for i := range annotations {
+		annotations[i] = CheckAnnotation{
+			Path:            "src/parser.go",
+			StartLine:       i + 1,
+			EndLine:         i + 1,
+			AnnotationLevel: AnnotationWarning,
+			Message:         fmt.Sprintf("Line %d not covered by tests", i+1),
+		}
+	}
+	return annotations
+}
+
+func TestClient_CreateCheckRun(t *testing.T) {
+	var batches []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Name       string         `json:"name"`
+			HeadSHA    string         `json:"head_sha"`
+			Status     string         `json:"status"`
+			Conclusion string         `json:"conclusion"`
+			Output     checkRunOutput `json:"output"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatalf("failed to decode payload: %v", err)
+		}
+		if payload.Output.Title != "Coverage 80.00%" || payload.Output.Summary != "## Coverage Report" {
+			t.Errorf("unexpected output: %+v", payload.Output)
+		}
+		batches = append(batches, len(payload.Output.Annotations))
+
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/repos/owner/repo/check-runs":
+			if payload.Name != "litecov" || payload.HeadSHA != "abc123" {
+				t.Errorf("unexpected check run: %+v", payload)
+			}
+			if payload.Status != "completed" || payload.Conclusion != ConclusionSuccess {
+				t.Errorf("unexpected status %q conclusion %q", payload.Status, payload.Conclusion)
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": 99}`))
+		case r.Method == "PATCH" && r.URL.Path == "/repos/owner/repo/check-runs/99":
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "owner", Repo: "repo", BaseURL: server.URL}
+	id, err := client.CreateCheckRun(CheckRun{
+		Name:       "litecov",
+		HeadSHA:    "abc123",
+		Conclusion: ConclusionSuccess,
+		Title:      "Coverage 80.00%",
+		Summary:    "## Coverage Report",
+	}, testAnnotations(120))
+	if err != nil {
+		t.Fatalf("CreateCheckRun() error = %v", err)
+	}
+	if id != 99 {
+		t.Errorf("id = %d, want 99", id)
+	}
+
+	want := []int{50, 50, 20}
+	if fmt.Sprint(batches) != fmt.Sprint(want) {
+		t.Errorf("annotation batches = %v, want %v", batches, want)
+	}
+}
+
+func TestClient_CreateCheckRun_NoAnnotations(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL}
+	if _, err := client.CreateCheckRun(CheckRun{Name: "litecov", HeadSHA: "abc"}, nil); err != nil {
+		t.Fatalf("CreateCheckRun() error = %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests, want 1", requests)
+	}
+}
+
+func TestClient_CreateCheckRun_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL}
+	if _, err := client.CreateCheckRun(CheckRun{Name: "litecov", HeadSHA: "abc"}, nil); err == nil {
+		t.Error("expected error for 403 response")
+	}
+}
+
+func TestBatchAnnotations(t *testing.T) {
+	tests := []struct {
+		count    int
+		expected []int
+	}{
+		{0, nil},
+		{1, []int{1}},
+		{50, []int{50}},
+		{51, []int{50, 1}},
+		{150, []int{50, 50, 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.count), func(t *testing.T) {
+			var sizes []int
+			for _, b := range batchAnnotations(testAnnotations(tt.count)) {
+				sizes = append(sizes, len(b))
+			}
+			if fmt.Sprint(sizes) != fmt.Sprint(tt.expected) {
+				t.Errorf("batch sizes = %v, want %v", sizes, tt.expected)
+			}
+		})
+	}
+}
@@ -3,11 +3,33 @@ package github
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
+	"time"
+)
+
+const (
+	// DefaultBaseURL is the API endpoint of github.com.
+	DefaultBaseURL = "https://api.github.com"
+	// DefaultMaxRetries is the number of retries NewClient configures.
+	DefaultMaxRetries = 3
+	// DefaultTimeout is the per-request timeout NewClient configures.
+	DefaultTimeout = 30 * time.Second
+
+	// perPage is the page size requested from list endpoints.
+	perPage = 100
+	// maxRetryWait is the longest delay worth waiting for before a retry.
+	// Rate limits that reset later than this fail immediately.
+	maxRetryWait = time.Minute
 )
 
 type Client struct {
@@ -15,86 +37,341 @@ type Client struct {
 	Owner   string
 	Repo    string
 	BaseURL string
+	// MaxRetries is how often a request is retried after a server error or
+	// a rate limit. POST requests, which are not idempotent, are only
+	// retried after a rate limit. Zero disables retries.
+	MaxRetries int
+	// Timeout bounds each request attempt, including reading the response
+	// body. Zero means no timeout.
+	Timeout time.Duration
+	// HTTPClient sends the requests. Nil uses http.DefaultClient.
+	HTTPClient *http.Client
+	// Auth supplies the token instead of Token when set, e.g. an AppAuth.
+	Auth TokenSource
+
+	// sleep waits between retries; tests replace it to avoid real delays.
+	sleep func(time.Duration)
 }
 
 func NewClient(token, owner, repo string) *Client {
+	return NewClientWithBaseURL(token, owner, repo, DefaultBaseURL)
+}
+
+// NewClientWithBaseURL creates a client for another API endpoint, such as
+// "https://ghe.example.com/api/v3" on GitHub Enterprise Server.
+func NewClientWithBaseURL(token, owner, repo, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
 	return &Client{
-		Token:   token,
-		Owner:   owner,
-		Repo:    repo,
-		BaseURL: "https://api.github.com",
+		Token:      token,
+		Owner:      owner,
+		Repo:       repo,
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		MaxRetries: DefaultMaxRetries,
+		Timeout:    DefaultTimeout,
+	}
+}
+
+// NewHTTPClientWithCABundle returns an HTTP client that trusts the PEM
+// certificates in caFile in addition to the system roots.
+func NewHTTPClientWithCABundle(caFile string) (*http.Client, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
 	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	return &http.Client{Transport: transport}, nil
 }
 
 func (c *Client) doRequest(method, path string, body io.Reader) (*http.Response, error) {
-	req, err := http.NewRequest(method, c.BaseURL+path, body)
+	return c.doRequestAccept(method, path, "application/vnd.github.v3+json", body)
+}
+
+// doRequestAccept performs a request asking for the given media type.
+func (c *Client) doRequestAccept(method, path, accept string, body io.Reader) (*http.Response, error) {
+	token, err := c.token()
 	if err != nil {
 		return nil, err
 	}
+	return c.doRequestToken(method, path, accept, token, body)
+}
 
-	req.Header.Set("Authorization", "Bearer "+c.Token)
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
+// token returns the token requests are authenticated with.
+func (c *Client) token() (string, error) {
+	if c.Auth == nil {
+		return c.Token, nil
+	}
+	token, err := c.Auth.Token()
+	if err != nil {
+		return "", fmt.Errorf("failed to get token: %w", err)
+	}
+	return token, nil
+}
+
+// doRequestToken performs a request authenticated with token. Server errors
+// and rate limits are retried up to MaxRetries times, see retryDelay.
+func (c *Client) doRequestToken(method, path, accept, token string, body io.Reader) (*http.Response, error) {
+	return c.doRequestURL(method, c.BaseURL+path, accept, token, body)
+}
+
+// doRequestURL performs a request to an absolute URL, like doRequestToken.
+func (c *Client) doRequestURL(method, url, accept, token string, body io.Reader) (*http.Response, error) {
+	var payload []byte
 	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
+		var err error
+		if payload, err = io.ReadAll(body); err != nil {
+			return nil, err
+		}
 	}
 
-	return http.DefaultClient.Do(req)
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := c.requestContext()
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", accept)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		resp, err := c.httpClient().Do(req)
+		if err == nil {
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+		} else {
+			cancel()
+		}
+
+		if attempt >= c.MaxRetries {
+			return resp, err
+		}
+		wait, retry := retryDelay(method, resp, err, attempt)
+		if !retry || wait > maxRetryWait {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		c.wait(wait)
+	}
 }
 
-func (c *Client) GetChangedFiles(prNumber int) ([]string, error) {
-	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/files", c.Owner, c.Repo, prNumber)
-	resp, err := c.doRequest("GET", path, nil)
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (c *Client) requestContext() (context.Context, context.CancelFunc) {
+	if c.Timeout > 0 {
+		return context.WithTimeout(context.Background(), c.Timeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+func (c *Client) wait(d time.Duration) {
+	if c.sleep != nil {
+		c.sleep(d)
+		return
+	}
+	time.Sleep(d)
+}
+
+// cancelOnClose releases the request context once the body is closed, so
+// the timeout also covers reading the response.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// retryDelay reports whether a request should be retried and how long to
+// wait first. Transport errors and 5xx responses back off exponentially;
+// rate limited responses wait as long as GitHub asks. A POST that failed
+// may still have taken effect, creating a comment or check run, so it is
+// only retried when it was rejected by a rate limit.
+func retryDelay(method string, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	backoff := time.Second << attempt
+	idempotent := method != http.MethodPost
 	if err != nil {
-		return nil, err
+		// An untrusted certificate will not become trusted by retrying
+		var certErr *tls.CertificateVerificationError
+		return backoff, idempotent && !errors.As(err, &certErr)
+	}
+	if resp.StatusCode >= 500 {
+		return backoff, idempotent
+	}
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("GitHub API error: %s - %s", resp.Status, string(body))
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			wait := time.Until(time.Unix(reset, 0))
+			if wait < 0 {
+				wait = 0
+			}
+			return wait + time.Second, true
+		}
 	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return backoff, true
+	}
+	// A plain 403 is a permission error, not a rate limit
+	return 0, false
+}
 
-	var files []struct {
-		Filename string `json:"filename"`
+// getPages requests every page of a list endpoint, passing each response
+// body to decode until it reports done or the last page is reached.
+func (c *Client) getPages(path string, decode func(body io.Reader) (done bool, err error)) error {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&files); err != nil {
-		return nil, err
+	next := fmt.Sprintf("%s%sper_page=%d", path, sep, perPage)
+
+	for next != "" {
+		resp, err := c.doRequest("GET", next, nil)
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return fmt.Errorf("GitHub API error: %s - %s", resp.Status, string(body))
+		}
+
+		done, err := decode(resp.Body)
+		resp.Body.Close()
+		if err != nil || done {
+			return err
+		}
+
+		next, err = c.nextPage(resp.Header.Get("Link"))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nextPage returns the path of the rel="next" link in a Link header, or ""
+// on the last page. Links to other hosts are rejected so the token is only
+// ever sent to BaseURL.
+func (c *Client) nextPage(link string) (string, error) {
+	for _, part := range strings.Split(link, ",") {
+		sections := strings.Split(part, ";")
+		if len(sections) < 2 {
+			continue
+		}
+		isNext := false
+		for _, param := range sections[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				isNext = true
+			}
+		}
+		if !isNext {
+			continue
+		}
+		url := strings.Trim(strings.TrimSpace(sections[0]), "<>")
+		if !strings.HasPrefix(url, c.BaseURL+"/") {
+			return "", fmt.Errorf("unexpected pagination link: %s", url)
+		}
+		return strings.TrimPrefix(url, c.BaseURL), nil
 	}
+	return "", nil
+}
+
+func (c *Client) GetChangedFiles(prNumber int) ([]string, error) {
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/files", c.Owner, c.Repo, prNumber)
 
-	result := make([]string, len(files))
-	for i, f := range files {
-		result[i] = f.Filename
+	var result []string
+	err := c.getPages(path, func(body io.Reader) (bool, error) {
+		var files []struct {
+			Filename string `json:"filename"`
+		}
+		if err := json.NewDecoder(body).Decode(&files); err != nil {
+			return false, err
+		}
+		for _, f := range files {
+			result = append(result, f.Filename)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return result, nil
 }
 
-func (c *Client) FindExistingComment(prNumber int, marker string) (int, error) {
-	path := fmt.Sprintf("/repos/%s/%s/issues/%d/comments", c.Owner, c.Repo, prNumber)
-	resp, err := c.doRequest("GET", path, nil)
+// GetPullRequestDiff returns the unified diff of a pull request.
+func (c *Client) GetPullRequestDiff(prNumber int) (string, error) {
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d", c.Owner, c.Repo, prNumber)
+	resp, err := c.doRequestAccept("GET", path, "application/vnd.github.v3.diff", nil)
 	if err != nil {
-		return 0, err
+		return "", err
 	}
 	defer resp.Body.Close()
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return 0, fmt.Errorf("GitHub API error: %s - %s", resp.Status, string(body))
+		return "", fmt.Errorf("GitHub API error: %s - %s", resp.Status, string(body))
 	}
+	return string(body), nil
+}
 
-	var comments []struct {
-		ID   int    `json:"id"`
-		Body string `json:"body"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&comments); err != nil {
-		return 0, err
-	}
+func (c *Client) FindExistingComment(prNumber int, marker string) (int, error) {
+	path := fmt.Sprintf("/repos/%s/%s/issues/%d/comments", c.Owner, c.Repo, prNumber)
 
-	for _, comment := range comments {
-		if strings.HasPrefix(comment.Body, marker) {
-			return comment.ID, nil
+	found := 0
+	err := c.getPages(path, func(body io.Reader) (bool, error) {
+		var comments []struct {
+			ID   int    `json:"id"`
+			Body string `json:"body"`
+		}
+		if err := json.NewDecoder(body).Decode(&comments); err != nil {
+			return false, err
+		}
+		for _, comment := range comments {
+			if strings.HasPrefix(comment.Body, marker) {
+				found = comment.ID
+				return true, nil
+			}
 		}
+		return false, nil
+	})
+	if err != nil {
+		return 0, err
 	}
-	return 0, nil
+	return found, nil
 }
 
 func (c *Client) CreateComment(prNumber int, body string) error {
@@ -2,9 +2,17 @@ package github
 
 import (
 	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestClient_GetChangedFiles(t *testing.T) {
@@ -301,3 +309,356 @@ func TestClient_doRequest_InvalidURL(t *testing.T) {
 		t.Error("expected error for invalid URL")
 	}
 }
+
+func TestClient_GetPullRequestDiff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/pulls/7" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("Accept") != "application/vnd.github.v3.diff" {
+			t.Errorf("unexpected accept header: %s", r.Header.Get("Accept"))
+		}
+		w.Write([]byte("diff --git a/a.go b/a.go\n"))
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "owner", Repo: "repo", BaseURL: server.URL}
+	diff, err := client.GetPullRequestDiff(7)
+	if err != nil {
+		t.Fatalf("GetPullRequestDiff() error = %v", err)
+	}
+	if diff != "diff --git a/a.go b/a.go\n" {
+		t.Errorf("GetPullRequestDiff() = %q", diff)
+	}
+}
+
+func TestClient_GetPullRequestDiff_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL}
+	if _, err := client.GetPullRequestDiff(1); err == nil {
+		t.Error("expected error for 404 response")
+	}
+}
+
+func TestClient_GetChangedFiles_Paginated(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("per_page") != "100" {
+			t.Errorf("per_page = %q, want 100", r.URL.Query().Get("per_page"))
+		}
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/o/r/pulls/1/files?per_page=100&page=2>; rel="next", <%s/repos/o/r/pulls/1/files?per_page=100&page=3>; rel="last"`, server.URL, server.URL))
+			w.Write([]byte(`[{"filename": "a.go"}, {"filename": "b.go"}]`))
+		case "2":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/o/r/pulls/1/files?per_page=100&page=3>; rel="next"`, server.URL))
+			w.Write([]byte(`[{"filename": "c.go"}]`))
+		case "3":
+			w.Write([]byte(`[{"filename": "d.go"}]`))
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL}
+	files, err := client.GetChangedFiles(1)
+	if err != nil {
+		t.Fatalf("GetChangedFiles() error = %v", err)
+	}
+	want := []string{"a.go", "b.go", "c.go", "d.go"}
+	if fmt.Sprint(files) != fmt.Sprint(want) {
+		t.Errorf("GetChangedFiles() = %v, want %v", files, want)
+	}
+}
+
+func TestClient_FindExistingComment_SecondPage(t *testing.T) {
+	requests := 0
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/o/r/issues/1/comments?per_page=100&page=2>; rel="next"`, server.URL))
+			w.Write([]byte(`[{"id": 1, "body": "LGTM"}]`))
+		case "2":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/o/r/issues/1/comments?per_page=100&page=3>; rel="next"`, server.URL))
+			w.Write([]byte(`[{"id": 42, "body": "<!-- litecov -->\n## Coverage Report"}]`))
+		default:
+			t.Error("should stop paginating once the comment is found")
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL}
+	id, err := client.FindExistingComment(1, "<!-- litecov -->")
+	if err != nil {
+		t.Fatalf("FindExistingComment() error = %v", err)
+	}
+	if id != 42 {
+		t.Errorf("FindExistingComment() = %v, want 42", id)
+	}
+	if requests != 2 {
+		t.Errorf("got %d requests, want 2", requests)
+	}
+}
+
+func TestClient_GetChangedFiles_ForeignLink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", `<https://evil.example.com/files?page=2>; rel="next"`)
+		w.Write([]byte(`[{"filename": "a.go"}]`))
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL}
+	if _, err := client.GetChangedFiles(1); err == nil {
+		t.Error("expected error for pagination link to another host")
+	}
+}
+
+func TestClient_RetriesServerErrors(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "test body") {
+			t.Errorf("request %d body = %q, want payload resent", requests, body)
+		}
+		if requests < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL, MaxRetries: 3}
+	client.sleep = func(d time.Duration) { waits = append(waits, d) }
+
+	if err := client.UpdateComment(1, "test body"); err != nil {
+		t.Fatalf("UpdateComment() error = %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("got %d requests, want 3", requests)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second}
+	if fmt.Sprint(waits) != fmt.Sprint(want) {
+		t.Errorf("backoff = %v, want %v", waits, want)
+	}
+}
+
+func TestClient_RetriesGiveUp(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL, MaxRetries: 2}
+	client.sleep = func(time.Duration) {}
+
+	if err := client.UpdateComment(1, "body"); err == nil {
+		t.Error("expected error after exhausting retries")
+	}
+	if requests != 3 {
+		t.Errorf("got %d requests, want 3", requests)
+	}
+}
+
+func TestClient_PostNotRetriedOnServerError(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL, MaxRetries: 3}
+	client.sleep = func(time.Duration) { t.Error("POST should not wait to be retried") }
+
+	if err := client.CreateComment(1, "body"); err == nil {
+		t.Error("expected error for 502 response")
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests, want the POST sent exactly once", requests)
+	}
+}
+
+func TestClient_PostRetriedOnRateLimit(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL, MaxRetries: 3}
+	client.sleep = func(time.Duration) {}
+
+	if err := client.CreateComment(1, "body"); err != nil {
+		t.Fatalf("CreateComment() error = %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("got %d requests, want a rejected POST retried once", requests)
+	}
+}
+
+func TestClient_RetriesRateLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		headers  map[string]string
+		retried  bool
+		minDelay time.Duration
+	}{
+		{"retry after", http.StatusForbidden, map[string]string{"Retry-After": "7"}, true, 7 * time.Second},
+		{"rate limit reset", http.StatusForbidden, map[string]string{
+			"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Reset":     strconv.FormatInt(time.Now().Add(10*time.Second).Unix(), 10),
+		}, true, 9 * time.Second},
+		{"too many requests", http.StatusTooManyRequests, nil, true, time.Second},
+		{"reset too far away", http.StatusForbidden, map[string]string{"Retry-After": "3600"}, false, 0},
+		{"permission error", http.StatusForbidden, nil, false, 0},
+		{"not found", http.StatusNotFound, nil, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests == 1 {
+					for k, v := range tt.headers {
+						w.Header().Set(k, v)
+					}
+					w.WriteHeader(tt.status)
+					return
+				}
+				w.Write([]byte(`[]`))
+			}))
+			defer server.Close()
+
+			var waits []time.Duration
+			client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL, MaxRetries: 3}
+			client.sleep = func(d time.Duration) { waits = append(waits, d) }
+
+			_, err := client.GetChangedFiles(1)
+			if tt.retried {
+				if err != nil {
+					t.Fatalf("GetChangedFiles() error = %v", err)
+				}
+				if len(waits) != 1 || waits[0] < tt.minDelay {
+					t.Errorf("waits = %v, want one wait of at least %v", waits, tt.minDelay)
+				}
+			} else {
+				if err == nil {
+					t.Error("expected error without retry")
+				}
+				if requests != 1 {
+					t.Errorf("got %d requests, want 1", requests)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL, Timeout: 50 * time.Millisecond}
+	if _, err := client.GetChangedFiles(1); err == nil {
+		t.Error("expected timeout error")
+	}
+}
+
+func TestNewClient_Defaults(t *testing.T) {
+	c := NewClient("token", "owner", "repo")
+	if c.MaxRetries != DefaultMaxRetries {
+		t.Errorf("MaxRetries = %d, want %d", c.MaxRetries, DefaultMaxRetries)
+	}
+	if c.Timeout != DefaultTimeout {
+		t.Errorf("Timeout = %v, want %v", c.Timeout, DefaultTimeout)
+	}
+}
+
+func TestNewClientWithBaseURL(t *testing.T) {
+	tests := []struct {
+		baseURL  string
+		expected string
+	}{
+		{"", DefaultBaseURL},
+		{"https://ghe.example.com/api/v3", "https://ghe.example.com/api/v3"},
+		{"https://ghe.example.com/api/v3/", "https://ghe.example.com/api/v3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.baseURL, func(t *testing.T) {
+			c := NewClientWithBaseURL("token", "owner", "repo", tt.baseURL)
+			if c.BaseURL != tt.expected {
+				t.Errorf("BaseURL = %v, want %v", c.BaseURL, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewHTTPClientWithCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"filename": "a.go"}]`))
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClientWithBaseURL("test", "o", "r", server.URL)
+	if _, err := client.GetChangedFiles(1); err == nil {
+		t.Fatal("expected certificate error without CA bundle")
+	}
+
+	httpClient, err := NewHTTPClientWithCABundle(caFile)
+	if err != nil {
+		t.Fatalf("NewHTTPClientWithCABundle() error = %v", err)
+	}
+	client.HTTPClient = httpClient
+	files, err := client.GetChangedFiles(1)
+	if err != nil {
+		t.Fatalf("GetChangedFiles() error = %v", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("got %d files, want 1", len(files))
+	}
+}
+
+func TestNewHTTPClientWithCABundle_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewHTTPClientWithCABundle(filepath.Join(dir, "missing.pem")); err == nil {
+		t.Error("expected error for missing file")
+	}
+
+	empty := filepath.Join(dir, "empty.pem")
+	os.WriteFile(empty, []byte("not a certificate"), 0644)
+	if _, err := NewHTTPClientWithCABundle(empty); err == nil {
+		t.Error("expected error for file without certificates")
+	}
+}
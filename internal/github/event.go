@@ -0,0 +1,186 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Event holds what litecov needs from the payload of the workflow trigger.
+type Event struct {
+	// Name is the event name, e.g. "pull_request".
+	Name string
+	// PRNumber is zero when the event is not about a pull request.
+	PRNumber int
+	// HeadSHA is the commit that was tested. On pull requests this is the
+	// head of the branch, not the merge commit in GITHUB_SHA.
+	HeadSHA string
+	BaseSHA string
+	// BaseRef is the target branch name, without "refs/heads/".
+	BaseRef string
+	// Fork reports whether the pull request comes from another repository.
+	Fork bool
+	// DefaultBranch is the repository's default branch, when known.
+	DefaultBranch string
+}
+
+// Repository is the repository part of an event payload.
+type Repository struct {
+	FullName      string `json:"full_name"`
+	Fork          bool   `json:"fork"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+// Branch is a head or base of a pull request.
+type Branch struct {
+	SHA  string      `json:"sha"`
+	Ref  string      `json:"ref"`
+	Repo *Repository `json:"repo"`
+}
+
+// PullRequest is a pull request as found in event payloads and API responses.
+type PullRequest struct {
+	Number int    `json:"number"`
+	Head   Branch `json:"head"`
+	Base   Branch `json:"base"`
+}
+
+// IsFork reports whether the head branch lives in another repository. A
+// deleted head repository is treated as a fork.
+func (pr *PullRequest) IsFork() bool {
+	if pr.Head.Repo == nil || pr.Base.Repo == nil {
+		return true
+	}
+	return pr.Head.Repo.FullName != pr.Base.Repo.FullName
+}
+
+type eventPayload struct {
+	Number      int          `json:"number"`
+	PullRequest *PullRequest `json:"pull_request"`
+	Repository  *Repository  `json:"repository"`
+
+	// push
+	After string `json:"after"`
+	Ref   string `json:"ref"`
+
+	WorkflowRun *struct {
+		HeadSHA        string        `json:"head_sha"`
+		PullRequests   []PullRequest `json:"pull_requests"`
+		HeadRepository *Repository   `json:"head_repository"`
+		Repository     *Repository   `json:"repository"`
+	} `json:"workflow_run"`
+
+	MergeGroup *struct {
+		HeadSHA string `json:"head_sha"`
+		BaseSHA string `json:"base_sha"`
+		BaseRef string `json:"base_ref"`
+	} `json:"merge_group"`
+
+	Issue *struct {
+		Number      int              `json:"number"`
+		PullRequest *json.RawMessage `json:"pull_request"`
+	} `json:"issue"`
+}
+
+// LoadEvent reads the event payload at path, usually GITHUB_EVENT_PATH. An
+// empty path yields an event without pull request details.
+func LoadEvent(name, path string) (*Event, error) {
+	if path == "" {
+		return &Event{Name: name}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseEvent(name, f)
+}
+
+// ParseEvent decodes the payload of the named event. Payloads of events that
+// litecov does not know are searched for a pull request in the usual place.
+func ParseEvent(name string, r io.Reader) (*Event, error) {
+	var payload eventPayload
+	if err := json.NewDecoder(r).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode %s event: %w", name, err)
+	}
+
+	event := &Event{Name: name}
+	if payload.Repository != nil {
+		event.DefaultBranch = payload.Repository.DefaultBranch
+	}
+	switch {
+	case name == "push":
+		event.HeadSHA = payload.After
+		event.BaseRef = strings.TrimPrefix(payload.Ref, "refs/heads/")
+
+	case name == "workflow_run" && payload.WorkflowRun != nil:
+		run := payload.WorkflowRun
+		event.HeadSHA = run.HeadSHA
+		if len(run.PullRequests) > 0 {
+			pr := run.PullRequests[0]
+			event.PRNumber = pr.Number
+			event.BaseSHA = pr.Base.SHA
+			event.BaseRef = pr.Base.Ref
+		}
+		if run.HeadRepository != nil && run.Repository != nil {
+			event.Fork = run.HeadRepository.FullName != run.Repository.FullName
+		}
+
+	case name == "merge_group" && payload.MergeGroup != nil:
+		event.HeadSHA = payload.MergeGroup.HeadSHA
+		event.BaseSHA = payload.MergeGroup.BaseSHA
+		event.BaseRef = strings.TrimPrefix(payload.MergeGroup.BaseRef, "refs/heads/")
+
+	case name == "issue_comment" && payload.Issue != nil:
+		// Comments on plain issues carry no pull_request field. The payload
+		// has no commit details; use Client.GetPullRequest for those.
+		if payload.Issue.PullRequest != nil {
+			event.PRNumber = payload.Issue.Number
+		}
+
+	case payload.PullRequest != nil:
+		// pull_request, pull_request_target and pull_request_review
+		event.SetPullRequest(payload.PullRequest)
+	}
+
+	return event, nil
+}
+
+// IsDefaultBranchPush reports whether the event is a push to the default
+// branch.
+func (e *Event) IsDefaultBranchPush() bool {
+	return e.Name == "push" && e.DefaultBranch != "" && e.BaseRef == e.DefaultBranch
+}
+
+// SetPullRequest fills the pull request details of the event.
+func (e *Event) SetPullRequest(pr *PullRequest) {
+	e.PRNumber = pr.Number
+	e.HeadSHA = pr.Head.SHA
+	e.BaseSHA = pr.Base.SHA
+	e.BaseRef = pr.Base.Ref
+	e.Fork = pr.IsFork()
+}
+
+// GetPullRequest returns a pull request with its head and base branches.
+func (c *Client) GetPullRequest(prNumber int) (*PullRequest, error) {
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d", c.Owner, c.Repo, prNumber)
+	resp, err := c.doRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("GitHub API error: %s - %s", resp.Status, string(body))
+	}
+
+	var pr PullRequest
+	if err := json.NewDecoder(resp.Body).Decode(&pr); err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
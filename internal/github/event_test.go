@@ -0,0 +1,215 @@
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    string
+		payload  string
+		expected Event
+	}{
+		{
+			name:  "pull request with nested number first",
+			event: "pull_request",
+			payload: `{
+				"action": "synchronize",
+				"milestone": {"number": 3},
+				"number": 42,
+				"pull_request": {
+					"number": 42,
+					"head": {"sha": "head123", "ref": "feature", "repo": {"full_name": "owner/repo"}},
+					"base": {"sha": "base456", "ref": "main", "repo": {"full_name": "owner/repo"}}
+				}
+			}`,
+			expected: Event{PRNumber: 42, HeadSHA: "head123", BaseSHA: "base456", BaseRef: "main"},
+		},
+		{
+			name:  "pull request target from fork",
+			event: "pull_request_target",
+			payload: `{
+				"number": 7,
+				"pull_request": {
+					"number": 7,
+					"head": {"sha": "head123", "ref": "patch-1", "repo": {"full_name": "someone/repo", "fork": true}},
+					"base": {"sha": "base456", "ref": "develop", "repo": {"full_name": "owner/repo"}}
+				}
+			}`,
+			expected: Event{PRNumber: 7, HeadSHA: "head123", BaseSHA: "base456", BaseRef: "develop", Fork: true},
+		},
+		{
+			name:  "pull request with deleted head repository",
+			event: "pull_request",
+			payload: `{
+				"pull_request": {
+					"number": 8,
+					"head": {"sha": "head123", "ref": "patch-1", "repo": null},
+					"base": {"sha": "base456", "ref": "main", "repo": {"full_name": "owner/repo"}}
+				}
+			}`,
+			expected: Event{PRNumber: 8, HeadSHA: "head123", BaseSHA: "base456", BaseRef: "main", Fork: true},
+		},
+		{
+			name:  "workflow run",
+			event: "workflow_run",
+			payload: `{
+				"workflow_run": {
+					"head_sha": "head123",
+					"pull_requests": [{"number": 9, "head": {"sha": "head123"}, "base": {"sha": "base456", "ref": "main"}}],
+					"head_repository": {"full_name": "someone/repo"},
+					"repository": {"full_name": "owner/repo"}
+				}
+			}`,
+			expected: Event{PRNumber: 9, HeadSHA: "head123", BaseSHA: "base456", BaseRef: "main", Fork: true},
+		},
+		{
+			name:  "workflow run from fork without pull requests",
+			event: "workflow_run",
+			payload: `{
+				"workflow_run": {
+					"head_sha": "head123",
+					"pull_requests": [],
+					"head_repository": {"full_name": "owner/repo"},
+					"repository": {"full_name": "owner/repo"}
+				}
+			}`,
+			expected: Event{HeadSHA: "head123"},
+		},
+		{
+			name:  "merge group",
+			event: "merge_group",
+			payload: `{
+				"merge_group": {"head_sha": "head123", "base_sha": "base456", "base_ref": "refs/heads/main"}
+			}`,
+			expected: Event{HeadSHA: "head123", BaseSHA: "base456", BaseRef: "main"},
+		},
+		{
+			name:  "comment on pull request",
+			event: "issue_comment",
+			payload: `{
+				"issue": {"number": 11, "pull_request": {"url": "https://api.github.com/repos/owner/repo/pulls/11"}},
+				"comment": {"id": 5}
+			}`,
+			expected: Event{PRNumber: 11},
+		},
+		{
+			name:     "comment on issue",
+			event:    "issue_comment",
+			payload:  `{"issue": {"number": 12}, "comment": {"id": 5}}`,
+			expected: Event{},
+		},
+		{
+			name:     "push",
+			event:    "push",
+			payload:  `{"ref": "refs/heads/main", "before": "old", "after": "new123", "repository": {"default_branch": "main"}}`,
+			expected: Event{HeadSHA: "new123", BaseRef: "main", DefaultBranch: "main"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := ParseEvent(tt.event, strings.NewReader(tt.payload))
+			if err != nil {
+				t.Fatalf("ParseEvent() error = %v", err)
+			}
+			tt.expected.Name = tt.event
+			if *event != tt.expected {
+				t.Errorf("ParseEvent() = %+v, want %+v", *event, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseEvent_Invalid(t *testing.T) {
+	if _, err := ParseEvent("pull_request", strings.NewReader("not json")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestLoadEvent(t *testing.T) {
+	event, err := LoadEvent("workflow_dispatch", "")
+	if err != nil {
+		t.Fatalf("LoadEvent() error = %v", err)
+	}
+	if event.Name != "workflow_dispatch" || event.PRNumber != 0 {
+		t.Errorf("LoadEvent() = %+v", event)
+	}
+
+	path := filepath.Join(t.TempDir(), "event.json")
+	os.WriteFile(path, []byte(`{"pull_request": {"number": 5, "head": {"sha": "abc"}}}`), 0644)
+	event, err = LoadEvent("pull_request", path)
+	if err != nil {
+		t.Fatalf("LoadEvent() error = %v", err)
+	}
+	if event.PRNumber != 5 || event.HeadSHA != "abc" {
+		t.Errorf("LoadEvent() = %+v", event)
+	}
+
+	if _, err := LoadEvent("pull_request", filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing event file")
+	}
+}
+
+func TestClient_GetPullRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/pulls/11" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{
+			"number": 11,
+			"head": {"sha": "head123", "ref": "feature", "repo": {"full_name": "owner/repo"}},
+			"base": {"sha": "base456", "ref": "main", "repo": {"full_name": "owner/repo"}}
+		}`))
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "owner", Repo: "repo", BaseURL: server.URL}
+	pr, err := client.GetPullRequest(11)
+	if err != nil {
+		t.Fatalf("GetPullRequest() error = %v", err)
+	}
+
+	event := &Event{Name: "issue_comment"}
+	event.SetPullRequest(pr)
+	expected := Event{Name: "issue_comment", PRNumber: 11, HeadSHA: "head123", BaseSHA: "base456", BaseRef: "main"}
+	if *event != expected {
+		t.Errorf("event = %+v, want %+v", *event, expected)
+	}
+}
+
+func TestClient_GetPullRequest_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL}
+	if _, err := client.GetPullRequest(1); err == nil {
+		t.Error("expected error for 404 response")
+	}
+}
+
+func TestEvent_IsDefaultBranchPush(t *testing.T) {
+	tests := []struct {
+		event    Event
+		expected bool
+	}{
+		{Event{Name: "push", BaseRef: "main", DefaultBranch: "main"}, true},
+		{Event{Name: "push", BaseRef: "feature", DefaultBranch: "main"}, false},
+		{Event{Name: "push", BaseRef: "main"}, false},
+		{Event{Name: "pull_request", BaseRef: "main", DefaultBranch: "main"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.event.IsDefaultBranchPush(); got != tt.expected {
+			t.Errorf("IsDefaultBranchPush(%+v) = %v, want %v", tt.event, got, tt.expected)
+		}
+	}
+}
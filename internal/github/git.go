@@ -0,0 +1,228 @@
+package github
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrNotFound is returned when a requested git object or ref does not exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrConflict is returned when a branch moved while it was being updated.
+var ErrConflict = errors.New("branch was updated concurrently")
+
+// TreeEntry is a file in a git tree. Content is only used when creating
+// trees, SHA only when reading them.
+type TreeEntry struct {
+	Path    string `json:"path"`
+	Mode    string `json:"mode,omitempty"`
+	Type    string `json:"type"`
+	SHA     string `json:"sha,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// GetBranchSHA returns the commit a branch points at, or ErrNotFound.
+func (c *Client) GetBranchSHA(branch string) (string, error) {
+	var ref struct {
+		Object struct {
+			SHA string `json:"sha"`
+		} `json:"object"`
+	}
+	path := fmt.Sprintf("/repos/%s/%s/git/ref/heads/%s", c.Owner, c.Repo, branch)
+	if err := c.getJSON(path, &ref); err != nil {
+		return "", err
+	}
+	return ref.Object.SHA, nil
+}
+
+// GetCommitTree returns the tree SHA of a commit.
+func (c *Client) GetCommitTree(commitSHA string) (string, error) {
+	var commit struct {
+		Tree struct {
+			SHA string `json:"sha"`
+		} `json:"tree"`
+	}
+	path := fmt.Sprintf("/repos/%s/%s/git/commits/%s", c.Owner, c.Repo, commitSHA)
+	if err := c.getJSON(path, &commit); err != nil {
+		return "", err
+	}
+	return commit.Tree.SHA, nil
+}
+
+// GetTree lists the entries directly inside a tree or commit. Directories
+// are entries of type "tree" and can be listed by their SHA. Listing one
+// level at a time keeps each response well below the size at which GitHub
+// truncates recursive listings.
+func (c *Client) GetTree(treeish string) ([]TreeEntry, error) {
+	var tree struct {
+		Tree      []TreeEntry `json:"tree"`
+		Truncated bool        `json:"truncated"`
+	}
+	path := fmt.Sprintf("/repos/%s/%s/git/trees/%s", c.Owner, c.Repo, treeish)
+	if err := c.getJSON(path, &tree); err != nil {
+		return nil, err
+	}
+	if tree.Truncated {
+		return nil, fmt.Errorf("tree %s is too large to list", treeish)
+	}
+	return tree.Tree, nil
+}
+
+// GetBlob returns the content of a blob.
+func (c *Client) GetBlob(blobSHA string) ([]byte, error) {
+	var blob struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	path := fmt.Sprintf("/repos/%s/%s/git/blobs/%s", c.Owner, c.Repo, blobSHA)
+	if err := c.getJSON(path, &blob); err != nil {
+		return nil, err
+	}
+	if blob.Encoding != "base64" {
+		return []byte(blob.Content), nil
+	}
+	// GitHub wraps base64 content across lines
+	return base64.StdEncoding.DecodeString(strings.ReplaceAll(blob.Content, "\n", ""))
+}
+
+// CreateTree creates a tree from entries on top of baseTree. An empty
+// baseTree starts from an empty tree.
+func (c *Client) CreateTree(baseTree string, entries []TreeEntry) (string, error) {
+	request := map[string]interface{}{"tree": entries}
+	if baseTree != "" {
+		request["base_tree"] = baseTree
+	}
+	path := fmt.Sprintf("/repos/%s/%s/git/trees", c.Owner, c.Repo)
+	return c.createObject(path, request)
+}
+
+// CreateCommit creates a commit of tree. No parents makes an orphan commit.
+func (c *Client) CreateCommit(message, tree string, parents []string) (string, error) {
+	if parents == nil {
+		parents = []string{}
+	}
+	path := fmt.Sprintf("/repos/%s/%s/git/commits", c.Owner, c.Repo)
+	return c.createObject(path, map[string]interface{}{
+		"message": message,
+		"tree":    tree,
+		"parents": parents,
+	})
+}
+
+// CreateBranch creates a branch pointing at commitSHA.
+func (c *Client) CreateBranch(branch, commitSHA string) error {
+	path := fmt.Sprintf("/repos/%s/%s/git/refs", c.Owner, c.Repo)
+	payload, _ := json.Marshal(map[string]string{
+		"ref": "refs/heads/" + branch,
+		"sha": commitSHA,
+	})
+	return c.updateRef("POST", path, payload, http.StatusCreated)
+}
+
+// UpdateBranch fast-forwards a branch to commitSHA. It returns ErrConflict
+// when the branch no longer points at the parent of commitSHA.
+func (c *Client) UpdateBranch(branch, commitSHA string) error {
+	path := fmt.Sprintf("/repos/%s/%s/git/refs/heads/%s", c.Owner, c.Repo, branch)
+	payload, _ := json.Marshal(map[string]interface{}{
+		"sha":   commitSHA,
+		"force": false,
+	})
+	return c.updateRef("PATCH", path, payload, http.StatusOK)
+}
+
+// MergeBase returns the best common ancestor of two commits.
+func (c *Client) MergeBase(base, head string) (string, error) {
+	var comparison struct {
+		MergeBaseCommit struct {
+			SHA string `json:"sha"`
+		} `json:"merge_base_commit"`
+	}
+	path := fmt.Sprintf("/repos/%s/%s/compare/%s...%s", c.Owner, c.Repo, base, head)
+	if err := c.getJSON(path, &comparison); err != nil {
+		return "", err
+	}
+	return comparison.MergeBaseCommit.SHA, nil
+}
+
+// ListAncestors returns sha followed by up to limit-1 of its ancestors,
+// newest first.
+func (c *Client) ListAncestors(sha string, limit int) ([]string, error) {
+	var commits []struct {
+		SHA string `json:"sha"`
+	}
+	path := fmt.Sprintf("/repos/%s/%s/commits?sha=%s&per_page=%d", c.Owner, c.Repo, sha, limit)
+	if err := c.getJSON(path, &commits); err != nil {
+		return nil, err
+	}
+	result := make([]string, len(commits))
+	for i, commit := range commits {
+		result[i] = commit.SHA
+	}
+	return result, nil
+}
+
+// getJSON decodes the response of a GET request into v. A 404 response
+// returns ErrNotFound.
+func (c *Client) getJSON(path string, v interface{}) error {
+	resp, err := c.doRequest("GET", path, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GitHub API error: %s - %s", resp.Status, string(body))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// createObject posts a git object and returns its SHA.
+func (c *Client) createObject(path string, request interface{}) (string, error) {
+	payload, _ := json.Marshal(request)
+	resp, err := c.doRequest("POST", path, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("GitHub API error: %s - %s", resp.Status, string(body))
+	}
+
+	var created struct {
+		SHA string `json:"sha"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return "", err
+	}
+	return created.SHA, nil
+}
+
+func (c *Client) updateRef(method, path string, payload []byte, want int) error {
+	resp, err := c.doRequest(method, path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnprocessableEntity {
+		// Not a fast-forward, or the branch was created in the meantime
+		return ErrConflict
+	}
+	if resp.StatusCode != want {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GitHub API error: %s - %s", resp.Status, string(body))
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_GetBranchSHA(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r/git/ref/heads/litecov-data":
+			w.Write([]byte(`{"object": {"sha": "abc123"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL}
+	sha, err := client.GetBranchSHA("litecov-data")
+	if err != nil || sha != "abc123" {
+		t.Errorf("GetBranchSHA() = %q, %v", sha, err)
+	}
+	if _, err := client.GetBranchSHA("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetBranchSHA() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestClient_GetBlob(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// GitHub wraps base64 content with newlines
+		w.Write([]byte(`{"content": "eyJ2ZXJz\naW9uIjogMX0=\n", "encoding": "base64"}`))
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL}
+	data, err := client.GetBlob("blob")
+	if err != nil {
+		t.Fatalf("GetBlob() error = %v", err)
+	}
+	if string(data) != `{"version": 1}` {
+		t.Errorf("GetBlob() = %q", data)
+	}
+}
+
+func TestClient_GetTree_Truncated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Has("recursive") {
+			t.Error("tree should be listed one level at a time")
+		}
+		w.Write([]byte(`{"tree": [], "truncated": true}`))
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL}
+	if _, err := client.GetTree("main"); err == nil {
+		t.Error("expected error for truncated tree")
+	}
+}
+
+func TestClient_CreateCommit_Orphan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&req)
+		parents, ok := req["parents"].([]interface{})
+		if !ok || len(parents) != 0 {
+			t.Errorf("orphan commit should send empty parents, got %v", req["parents"])
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sha": "commit1"}`))
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL}
+	sha, err := client.CreateCommit("message", "tree1", nil)
+	if err != nil || sha != "commit1" {
+		t.Errorf("CreateCommit() = %q, %v", sha, err)
+	}
+}
+
+func TestClient_UpdateBranch_Conflict(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/repos/o/r/git/refs/heads/litecov-data" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"message": "Update is not a fast forward"}`))
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL}
+	if err := client.UpdateBranch("litecov-data", "commit1"); !errors.Is(err, ErrConflict) {
+		t.Errorf("UpdateBranch() error = %v, want ErrConflict", err)
+	}
+}
+
+func TestClient_MergeBaseAndAncestors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r/compare/base1...head1":
+			w.Write([]byte(`{"merge_base_commit": {"sha": "mb"}}`))
+		case "/repos/o/r/commits":
+			if r.URL.Query().Get("sha") != "mb" || r.URL.Query().Get("per_page") != "3" {
+				t.Errorf("unexpected query: %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`[{"sha": "mb"}, {"sha": "p1"}, {"sha": "p2"}]`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL}
+	mergeBase, err := client.MergeBase("base1", "head1")
+	if err != nil || mergeBase != "mb" {
+		t.Fatalf("MergeBase() = %q, %v", mergeBase, err)
+	}
+	ancestors, err := client.ListAncestors(mergeBase, 3)
+	if err != nil {
+		t.Fatalf("ListAncestors() error = %v", err)
+	}
+	if len(ancestors) != 3 || ancestors[0] != "mb" || ancestors[2] != "p2" {
+		t.Errorf("ListAncestors() = %v", ancestors)
+	}
+}
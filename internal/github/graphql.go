@@ -0,0 +1,153 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ReviewThread is a pull request review conversation. CommentID is the
+// database ID of its first comment, which is also the REST comment ID.
+type ReviewThread struct {
+	ID         string
+	IsResolved bool
+	CommentID  int64
+}
+
+const reviewThreadsQuery = `query($owner: String!, $repo: String!, $number: Int!, $after: String) {
+  repository(owner: $owner, name: $repo) {
+    pullRequest(number: $number) {
+      reviewThreads(first: 100, after: $after) {
+        nodes {
+          id
+          isResolved
+          comments(first: 1) { nodes { databaseId } }
+        }
+        pageInfo { hasNextPage endCursor }
+      }
+    }
+  }
+}`
+
+// ListReviewThreads returns every review thread on a pull request.
+func (c *Client) ListReviewThreads(prNumber int) ([]ReviewThread, error) {
+	var result []ReviewThread
+	var after *string
+	for {
+		var data struct {
+			Repository struct {
+				PullRequest struct {
+					ReviewThreads struct {
+						Nodes []struct {
+							ID         string `json:"id"`
+							IsResolved bool   `json:"isResolved"`
+							Comments   struct {
+								Nodes []struct {
+									DatabaseID int64 `json:"databaseId"`
+								} `json:"nodes"`
+							} `json:"comments"`
+						} `json:"nodes"`
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+					} `json:"reviewThreads"`
+				} `json:"pullRequest"`
+			} `json:"repository"`
+		}
+		err := c.graphQL(reviewThreadsQuery, map[string]interface{}{
+			"owner":  c.Owner,
+			"repo":   c.Repo,
+			"number": prNumber,
+			"after":  after,
+		}, &data)
+		if err != nil {
+			return nil, err
+		}
+
+		threads := data.Repository.PullRequest.ReviewThreads
+		for _, node := range threads.Nodes {
+			thread := ReviewThread{ID: node.ID, IsResolved: node.IsResolved}
+			if len(node.Comments.Nodes) > 0 {
+				thread.CommentID = node.Comments.Nodes[0].DatabaseID
+			}
+			result = append(result, thread)
+		}
+		if !threads.PageInfo.HasNextPage {
+			return result, nil
+		}
+		cursor := threads.PageInfo.EndCursor
+		after = &cursor
+	}
+}
+
+// ResolveReviewThread marks a review thread as resolved.
+func (c *Client) ResolveReviewThread(threadID string) error {
+	return c.graphQL(`mutation($id: ID!) {
+  resolveReviewThread(input: {threadId: $id}) { thread { id } }
+}`, map[string]interface{}{"id": threadID}, nil)
+}
+
+// UnresolveReviewThread reopens a resolved review thread.
+func (c *Client) UnresolveReviewThread(threadID string) error {
+	return c.graphQL(`mutation($id: ID!) {
+  unresolveReviewThread(input: {threadId: $id}) { thread { id } }
+}`, map[string]interface{}{"id": threadID}, nil)
+}
+
+// graphQLURL returns the GraphQL endpoint next to BaseURL: /graphql on
+// github.com and /api/graphql on GitHub Enterprise Server.
+func (c *Client) graphQLURL() string {
+	if base, ok := strings.CutSuffix(c.BaseURL, "/api/v3"); ok {
+		return base + "/api/graphql"
+	}
+	return c.BaseURL + "/graphql"
+}
+
+// graphQL runs a query or mutation and decodes its data into v, which may
+// be nil. Errors reported in the response are returned as an error.
+func (c *Client) graphQL(query string, variables map[string]interface{}, v interface{}) error {
+	token, err := c.token()
+	if err != nil {
+		return err
+	}
+	payload, _ := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	resp, err := c.doRequestURL("POST", c.graphQLURL(), "application/json", token, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GitHub API error: %s - %s", resp.Status, string(body))
+	}
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		messages := make([]string, len(result.Errors))
+		for i, e := range result.Errors {
+			messages[i] = e.Message
+		}
+		return errors.New("GitHub GraphQL error: " + strings.Join(messages, "; "))
+	}
+	if v == nil {
+		return nil
+	}
+	return json.Unmarshal(result.Data, v)
+}
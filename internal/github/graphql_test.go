@@ -0,0 +1,102 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_ListReviewThreads(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/graphql" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var req struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Variables["owner"] != "owner" || req.Variables["repo"] != "repo" || req.Variables["number"] != float64(3) {
+			t.Errorf("variables = %v", req.Variables)
+		}
+
+		requests++
+		if req.Variables["after"] == nil {
+			w.Write([]byte(`{"data": {"repository": {"pullRequest": {"reviewThreads": {
+				"nodes": [{"id": "T1", "isResolved": false, "comments": {"nodes": [{"databaseId": 11}]}}],
+				"pageInfo": {"hasNextPage": true, "endCursor": "c1"}}}}}}`))
+			return
+		}
+		if req.Variables["after"] != "c1" {
+			t.Errorf("after = %v, want c1", req.Variables["after"])
+		}
+		w.Write([]byte(`{"data": {"repository": {"pullRequest": {"reviewThreads": {
+			"nodes": [{"id": "T2", "isResolved": true, "comments": {"nodes": [{"databaseId": 12}]}}],
+			"pageInfo": {"hasNextPage": false, "endCursor": "c2"}}}}}}`))
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "owner", Repo: "repo", BaseURL: server.URL}
+	threads, err := client.ListReviewThreads(3)
+	if err != nil {
+		t.Fatalf("ListReviewThreads() error = %v", err)
+	}
+	want := []ReviewThread{{ID: "T1", CommentID: 11}, {ID: "T2", IsResolved: true, CommentID: 12}}
+	if len(threads) != 2 || threads[0] != want[0] || threads[1] != want[1] {
+		t.Errorf("ListReviewThreads() = %+v, want %+v", threads, want)
+	}
+	if requests != 2 {
+		t.Errorf("got %d requests, want 2", requests)
+	}
+}
+
+func TestClient_ResolveReviewThread(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// GitHub Enterprise Server serves GraphQL next to the REST API
+		if r.URL.Path != "/api/graphql" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		var req struct {
+			Query     string            `json:"query"`
+			Variables map[string]string `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		query = req.Query
+		if req.Variables["id"] != "T1" {
+			t.Errorf("id = %q, want T1", req.Variables["id"])
+		}
+		w.Write([]byte(`{"data": {}}`))
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "owner", Repo: "repo", BaseURL: server.URL + "/api/v3"}
+	if err := client.ResolveReviewThread("T1"); err != nil {
+		t.Fatalf("ResolveReviewThread() error = %v", err)
+	}
+	if !strings.Contains(query, "resolveReviewThread") {
+		t.Errorf("query = %q", query)
+	}
+	if err := client.UnresolveReviewThread("T1"); err != nil {
+		t.Fatalf("UnresolveReviewThread() error = %v", err)
+	}
+	if !strings.Contains(query, "unresolveReviewThread") {
+		t.Errorf("query = %q", query)
+	}
+}
+
+func TestClient_GraphQLErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data": null, "errors": [{"message": "Resource not accessible by integration"}]}`))
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "owner", Repo: "repo", BaseURL: server.URL}
+	err := client.ResolveReviewThread("T1")
+	if err == nil || !strings.Contains(err.Error(), "Resource not accessible by integration") {
+		t.Errorf("ResolveReviewThread() error = %v, want the GraphQL error", err)
+	}
+}
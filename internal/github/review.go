@@ -0,0 +1,103 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ReviewComment is a pull request review comment attached to lines of a file.
+type ReviewComment struct {
+	ID   int64  `json:"id"`
+	Path string `json:"path"`
+	// StartLine is the first line of a multi-line comment, zero otherwise.
+	StartLine int    `json:"start_line,omitempty"`
+	Line      int    `json:"line"`
+	Body      string `json:"body"`
+}
+
+// reviewCommentInput is a comment in a create review request. Comments are
+// placed on the new version of the file.
+type reviewCommentInput struct {
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line,omitempty"`
+	StartSide string `json:"start_side,omitempty"`
+	Line      int    `json:"line"`
+	Side      string `json:"side"`
+	Body      string `json:"body"`
+}
+
+// ListReviewComments returns every review comment on a pull request.
+func (c *Client) ListReviewComments(prNumber int) ([]ReviewComment, error) {
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/comments", c.Owner, c.Repo, prNumber)
+
+	var result []ReviewComment
+	err := c.getPages(path, func(body io.Reader) (bool, error) {
+		var comments []ReviewComment
+		if err := json.NewDecoder(body).Decode(&comments); err != nil {
+			return false, err
+		}
+		result = append(result, comments...)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreateReview posts a review with line comments on a pull request. An empty
+// commitID reviews the pull request's latest commit.
+func (c *Client) CreateReview(prNumber int, commitID, body string, comments []ReviewComment) error {
+	inputs := make([]reviewCommentInput, len(comments))
+	for i, rc := range comments {
+		inputs[i] = reviewCommentInput{Path: rc.Path, Line: rc.Line, Side: "RIGHT", Body: rc.Body}
+		if rc.StartLine > 0 && rc.StartLine < rc.Line {
+			inputs[i].StartLine = rc.StartLine
+			inputs[i].StartSide = "RIGHT"
+		}
+	}
+
+	review := map[string]interface{}{
+		"body":     body,
+		"event":    "COMMENT",
+		"comments": inputs,
+	}
+	if commitID != "" {
+		review["commit_id"] = commitID
+	}
+	payload, _ := json.Marshal(review)
+
+	path := fmt.Sprintf("/repos/%s/%s/pulls/%d/reviews", c.Owner, c.Repo, prNumber)
+	resp, err := c.doRequest("POST", path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GitHub API error: %s - %s", resp.Status, string(respBody))
+	}
+	return nil
+}
+
+// UpdateReviewComment replaces the body of a review comment.
+func (c *Client) UpdateReviewComment(commentID int64, body string) error {
+	path := fmt.Sprintf("/repos/%s/%s/pulls/comments/%d", c.Owner, c.Repo, commentID)
+	payload, _ := json.Marshal(map[string]string{"body": body})
+
+	resp, err := c.doRequest("PATCH", path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GitHub API error: %s - %s", resp.Status, string(respBody))
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_ListReviewComments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/pulls/3/comments" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`[
+			{"id": 1, "path": "a.go", "start_line": null, "line": 4, "body": "single"},
+			{"id": 2, "path": "a.go", "start_line": 6, "line": 8, "body": "range"}
+		]`))
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "owner", Repo: "repo", BaseURL: server.URL}
+	comments, err := client.ListReviewComments(3)
+	if err != nil {
+		t.Fatalf("ListReviewComments() error = %v", err)
+	}
+	if len(comments) != 2 {
+		t.Fatalf("got %d comments, want 2", len(comments))
+	}
+	if comments[0].StartLine != 0 || comments[0].Line != 4 {
+		t.Errorf("comments[0] = %+v", comments[0])
+	}
+	if comments[1].ID != 2 || comments[1].StartLine != 6 || comments[1].Line != 8 {
+		t.Errorf("comments[1] = %+v", comments[1])
+	}
+}
+
+func TestClient_CreateReview(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/repos/owner/repo/pulls/3/reviews" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var review struct {
+			CommitID string               `json:"commit_id"`
+			Body     string               `json:"body"`
+			Event    string               `json:"event"`
+			Comments []reviewCommentInput `json:"comments"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			t.Fatalf("failed to decode review: %v", err)
+		}
+		if review.CommitID != "abc123" || review.Event != "COMMENT" || review.Body != "summary" {
+			t.Errorf("unexpected review: %+v", review)
+		}
+		if len(review.Comments) != 2 {
+			t.Fatalf("got %d comments, want 2", len(review.Comments))
+		}
+		single, multi := review.Comments[0], review.Comments[1]
+		if single.StartLine != 0 || single.StartSide != "" || single.Line != 4 || single.Side != "RIGHT" {
+			t.Errorf("unexpected single line comment: %+v", single)
+		}
+		if multi.StartLine != 6 || multi.StartSide != "RIGHT" || multi.Line != 8 {
+			t.Errorf("unexpected multi-line comment: %+v", multi)
+		}
+		w.Write([]byte(`{"id": 10}`))
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "owner", Repo: "repo", BaseURL: server.URL}
+	err := client.CreateReview(3, "abc123", "summary", []ReviewComment{
+		{Path: "a.go", Line: 4, Body: "single"},
+		{Path: "a.go", StartLine: 6, Line: 8, Body: "range"},
+	})
+	if err != nil {
+		t.Fatalf("CreateReview() error = %v", err)
+	}
+}
+
+func TestClient_CreateReview_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"message": "Line could not be resolved"}`))
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL}
+	if err := client.CreateReview(1, "", "summary", []ReviewComment{{Path: "a.go", Line: 1}}); err == nil {
+		t.Error("expected error for 422 response")
+	}
+}
+
+func TestClient_UpdateReviewComment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/repos/owner/repo/pulls/comments/42" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		var payload map[string]string
+		json.NewDecoder(r.Body).Decode(&payload)
+		if payload["body"] != "resolved" {
+			t.Errorf("body = %q, want resolved", payload["body"])
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &Client{Token: "test", Owner: "owner", Repo: "repo", BaseURL: server.URL}
+	if err := client.UpdateReviewComment(42, "resolved"); err != nil {
+		t.Fatalf("UpdateReviewComment() error = %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+// Package htmlreport renders a self-contained static HTML coverage report: a
+// directory tree with per-directory rollups and an annotated source view of
+// every file.
+package htmlreport
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/manashmandal/litecov/internal/coverage"
+	"github.com/manashmandal/litecov/internal/paths"
+)
+
+//go:embed templates/*.html assets/style.css
+var content embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"pct": func(v float64) string { return fmt.Sprintf("%.2f%%", v) },
+}).ParseFS(content, "templates/*.html"))
+
+// Line classes of the source view.
+const (
+	lineCovered   = "covered"
+	linePartial   = "partial"
+	lineUncovered = "uncovered"
+)
+
+// filesDir holds the source pages inside the output directory.
+const filesDir = "files"
+
+// Options controls the content of the report.
+type Options struct {
+	Title string
+	// SourceRoot is the checkout source files are read from.
+	SourceRoot string
+	// RepoURL and SHA, when set, link each file to its source on GitHub.
+	RepoURL string
+	SHA     string
+	// Buckets colour the coverage bars. Nil uses coverage.DefaultBuckets.
+	Buckets coverage.Buckets
+}
+
+type indexPage struct {
+	Title string
+	Root  string
+	SHA   string
+	Files int
+	Tree  dirView
+}
+
+type dirView struct {
+	Name     string
+	Covered  int
+	Total    int
+	Coverage float64
+	Color    string
+	Dirs     []dirView
+	Files    []fileView
+}
+
+type fileView struct {
+	Name     string
+	Link     string
+	Covered  int
+	Total    int
+	Coverage float64
+	Color    string
+}
+
+type filePage struct {
+	Title     string
+	Root      string
+	Path      string
+	Covered   int
+	Total     int
+	Coverage  float64
+	SourceURL string
+	// Missing is set when the source could not be read from the checkout.
+	Missing bool
+	Lines   []sourceLine
+}
+
+type sourceLine struct {
+	Number int
+	Hits   string
+	Class  string
+	Text   string
+}
+
+// Write renders the report into dir as index.html, style.css and one page
+// per file under files/. The directory is created if needed.
+func Write(dir string, report *coverage.Report, opts Options) error {
+	if opts.Title == "" {
+		opts.Title = "Coverage Report"
+	}
+	if err := os.MkdirAll(filepath.Join(dir, filesDir), 0o755); err != nil {
+		return err
+	}
+
+	css, err := content.ReadFile("assets/style.css")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "style.css"), css, 0o644); err != nil {
+		return err
+	}
+
+	links := pageNames(report.Files)
+	for _, f := range report.Files {
+		page := newFilePage(f, opts)
+		page.Title = f.Path + " - " + opts.Title
+		if err := render(filepath.Join(dir, filesDir, links[f.Path]), "file.html", page); err != nil {
+			return err
+		}
+	}
+
+	index := indexPage{
+		Title: opts.Title,
+		SHA:   opts.SHA,
+		Files: len(report.Files),
+		Tree:  newDirView(coverage.NewTree(report.Files), links, opts.Buckets),
+	}
+	return render(filepath.Join(dir, "index.html"), "index.html", index)
+}
+
+func render(file, name string, data interface{}) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := templates.ExecuteTemplate(f, name, data); err != nil {
+		f.Close()
+		return fmt.Errorf("render %s: %w", file, err)
+	}
+	return f.Close()
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// pageNames maps file paths to unique page names under files/.
+func pageNames(files []coverage.FileCoverage) map[string]string {
+	names := make(map[string]string, len(files))
+	used := make(map[string]bool, len(files))
+	for _, f := range files {
+		base := unsafeNameChars.ReplaceAllString(strings.Trim(f.Path, "/"), "_")
+		name := base + ".html"
+		for i := 2; used[name]; i++ {
+			name = fmt.Sprintf("%s-%d.html", base, i)
+		}
+		used[name] = true
+		names[f.Path] = name
+	}
+	return names
+}
+
+func newDirView(d *coverage.Dir, links map[string]string, buckets coverage.Buckets) dirView {
+	view := dirView{
+		Name:     d.Name,
+		Covered:  d.Covered,
+		Total:    d.Total,
+		Coverage: d.Coverage(),
+		Color:    buckets.Find(d.Coverage()).Color,
+	}
+	for _, c := range d.Dirs {
+		view.Dirs = append(view.Dirs, newDirView(c, links, buckets))
+	}
+	for _, f := range d.Files {
+		view.Files = append(view.Files, fileView{
+			Name:     path.Base(f.Path),
+			Link:     filesDir + "/" + links[f.Path],
+			Covered:  f.LinesCovered,
+			Total:    f.LinesTotal,
+			Coverage: f.Percentage(),
+			Color:    buckets.Find(f.Percentage()).Color,
+		})
+	}
+	return view
+}
+
+// newFilePage annotates each source line of f with its hit count and class.
+// Without the source, only measured lines are listed.
+func newFilePage(f coverage.FileCoverage, opts Options) filePage {
+	page := filePage{
+		Root:     "../",
+		Path:     f.Path,
+		Covered:  f.LinesCovered,
+		Total:    f.LinesTotal,
+		Coverage: f.Percentage(),
+	}
+	if opts.RepoURL != "" && opts.SHA != "" {
+		page.SourceURL = fmt.Sprintf("%s/blob/%s/%s", strings.TrimRight(opts.RepoURL, "/"), opts.SHA, paths.NormalizePathForAnnotation(f.Path))
+	}
+
+	classes := make(map[int]string, f.LinesTotal)
+	for _, l := range f.CoveredLines {
+		classes[l] = lineCovered
+	}
+	for _, l := range f.PartialLines {
+		classes[l] = linePartial
+	}
+	for _, l := range f.UncoveredLines {
+		classes[l] = lineUncovered
+	}
+	annotate := func(number int, text string) sourceLine {
+		line := sourceLine{Number: number, Class: classes[number], Text: text}
+		if hits, ok := f.LineHits[number]; ok {
+			line.Hits = fmt.Sprintf("%d", hits)
+		}
+		return line
+	}
+
+	src, err := paths.OpenSource(opts.SourceRoot, f.Path)
+	if err != nil {
+		page.Missing = true
+		for _, number := range measuredLines(classes) {
+			page.Lines = append(page.Lines, annotate(number, ""))
+		}
+		return page
+	}
+	defer src.Close()
+
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for number := 1; scanner.Scan(); number++ {
+		page.Lines = append(page.Lines, annotate(number, scanner.Text()))
+	}
+	return page
+}
+
+func measuredLines(classes map[int]string) []int {
+	lines := make([]int, 0, len(classes))
+	for l := range classes {
+		lines = append(lines, l)
+	}
+	sort.Ints(lines)
+	return lines
+}
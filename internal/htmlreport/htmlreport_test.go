@@ -0,0 +1,124 @@
+package htmlreport
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/manashmandal/litecov/internal/coverage"
+)
+
+func testReport() *coverage.Report {
+	report := &coverage.Report{Files: []coverage.FileCoverage{
+		{
+			Path:           "pkg/calc.go",
+			LinesCovered:   2,
+			LinesTotal:     3,
+			CoveredLines:   []int{2, 3},
+			UncoveredLines: []int{4},
+			PartialLines:   []int{3},
+			LineHits:       map[int]int{2: 7, 3: 1, 4: 0},
+		},
+		{
+			Path:           "pkg/sub/missing.go",
+			LinesCovered:   0,
+			LinesTotal:     1,
+			UncoveredLines: []int{5},
+		},
+	}}
+	report.Calculate()
+	return report
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile(%s) error = %v", path, err)
+	}
+	return string(data)
+}
+
+func TestWrite(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	source := "package pkg\n\nfunc Add(a, b int) int {\n\treturn a + b // <sum>\n}\n"
+	if err := os.WriteFile(filepath.Join(root, "pkg", "calc.go"), []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(t.TempDir(), "html")
+	err := Write(out, testReport(), Options{
+		Title:      "My Coverage",
+		SourceRoot: root,
+		RepoURL:    "https://github.com/o/r",
+		SHA:        "abc123",
+		Buckets:    coverage.Buckets{{Min: 60, Color: "#4c1"}, {Min: 0, Color: "#e05d44"}},
+	})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(out, "style.css")); err != nil {
+		t.Errorf("style.css not written: %v", err)
+	}
+
+	index := readFile(t, filepath.Join(out, "index.html"))
+	for _, want := range []string{
+		"<title>My Coverage</title>",
+		"50.00% of lines covered (2/4) in 2 files",
+		`<span class="name">pkg/</span><span class="lines">2/4</span>`,
+		`<span class="name">sub/</span>`,
+		`href="files/pkg_calc.go.html"`,
+		`<span class="pct">66.67%</span>`,
+		`style="width: 66.7%; background: #4c1"`,
+		`style="width: 50.0%; background: #e05d44"`,
+	} {
+		if !strings.Contains(index, want) {
+			t.Errorf("index.html missing %q:\n%s", want, index)
+		}
+	}
+
+	page := readFile(t, filepath.Join(out, "files", "pkg_calc.go.html"))
+	for _, want := range []string{
+		`href="../style.css"`,
+		`href="https://github.com/o/r/blob/abc123/pkg/calc.go"`,
+		`<tr id="L1" class=""><td class="ln"><a href="#L1">1</a></td><td class="hits"></td><td class="code">package pkg</td></tr>`,
+		`<tr id="L2" class="covered">`,
+		`<td class="hits">7</td>`,
+		`<tr id="L3" class="partial">`,
+		`<tr id="L4" class="uncovered">`,
+		"return a &#43; b // &lt;sum&gt;",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("source page missing %q:\n%s", want, page)
+		}
+	}
+
+	missing := readFile(t, filepath.Join(out, "files", "pkg_sub_missing.go.html"))
+	if !strings.Contains(missing, "Source not found") || !strings.Contains(missing, `<tr id="L5" class="uncovered">`) {
+		t.Errorf("page without source should list measured lines:\n%s", missing)
+	}
+}
+
+func TestPageNames(t *testing.T) {
+	names := pageNames([]coverage.FileCoverage{
+		{Path: "a/b.go"},
+		{Path: "a_b.go"},
+		{Path: "/abs/c d.go"},
+	})
+
+	want := map[string]string{
+		"a/b.go":      "a_b.go.html",
+		"a_b.go":      "a_b.go-2.html",
+		"/abs/c d.go": "abs_c_d.go.html",
+	}
+	for path, name := range want {
+		if names[path] != name {
+			t.Errorf("pageNames()[%q] = %q, want %q", path, names[path], name)
+		}
+	}
+}
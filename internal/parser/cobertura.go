@@ -4,6 +4,7 @@ import (
 	"encoding/xml"
 	"io"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/manashmandal/litecov/internal/coverage"
@@ -31,6 +32,8 @@ type coberturaClass struct {
 type coberturaLine struct {
 	Number int `xml:"number,attr"`
 	Hits   int `xml:"hits,attr"`
+	// ConditionCoverage reads like "50% (1/2)" on branch lines.
+	ConditionCoverage string `xml:"condition-coverage,attr"`
 }
 
 func (p *CoberturaParser) Parse(r io.Reader) (*coverage.Report, error) {
@@ -41,6 +44,7 @@ func (p *CoberturaParser) Parse(r io.Reader) (*coverage.Report, error) {
 
 	fileMap := make(map[string]*coverage.FileCoverage)
 	linesSeen := make(map[string]map[int]bool)
+	branches := make(map[string]map[int]*branchCount)
 
 	for _, pkg := range cov.Packages {
 		for _, class := range pkg.Classes {
@@ -49,9 +53,10 @@ func (p *CoberturaParser) Parse(r io.Reader) (*coverage.Report, error) {
 
 			fc, exists := fileMap[filename]
 			if !exists {
-				fc = &coverage.FileCoverage{Path: filename}
+				fc = &coverage.FileCoverage{Path: filename, LineHits: make(map[int]int)}
 				fileMap[filename] = fc
 				linesSeen[filename] = make(map[int]bool)
+				branches[filename] = make(map[int]*branchCount)
 			}
 			for _, line := range class.Lines {
 				// Skip duplicate lines (same line number seen in multiple classes)
@@ -60,8 +65,13 @@ func (p *CoberturaParser) Parse(r io.Reader) (*coverage.Report, error) {
 				}
 				linesSeen[filename][line.Number] = true
 				fc.LinesTotal++
+				fc.LineHits[line.Number] = line.Hits
+				if b, ok := parseConditionCoverage(line.ConditionCoverage); ok {
+					branches[filename][line.Number] = b
+				}
 				if line.Hits > 0 {
 					fc.LinesCovered++
+					fc.CoveredLines = append(fc.CoveredLines, line.Number)
 				} else {
 					fc.UncoveredLines = append(fc.UncoveredLines, line.Number)
 				}
@@ -70,7 +80,8 @@ func (p *CoberturaParser) Parse(r io.Reader) (*coverage.Report, error) {
 	}
 
 	report := &coverage.Report{}
-	for _, fc := range fileMap {
+	for filename, fc := range fileMap {
+		fc.PartialLines = partialLines(fc.LineHits, branches[filename])
 		report.Files = append(report.Files, *fc)
 	}
 
@@ -78,6 +89,28 @@ func (p *CoberturaParser) Parse(r io.Reader) (*coverage.Report, error) {
 	return report, nil
 }
 
+// parseConditionCoverage reads the taken and total branch counts from a
+// condition-coverage attribute such as "50% (1/2)".
+func parseConditionCoverage(s string) (*branchCount, bool) {
+	open := strings.Index(s, "(")
+	if open < 0 || !strings.HasSuffix(s, ")") {
+		return nil, false
+	}
+	taken, total, ok := strings.Cut(s[open+1:len(s)-1], "/")
+	if !ok {
+		return nil, false
+	}
+	b := &branchCount{}
+	var err error
+	if b.taken, err = strconv.Atoi(strings.TrimSpace(taken)); err != nil {
+		return nil, false
+	}
+	if b.total, err = strconv.Atoi(strings.TrimSpace(total)); err != nil {
+		return nil, false
+	}
+	return b, true
+}
+
 // resolveFilename resolves a filename from coverage data using the sources list.
 // For pytest-cov, filenames are relative to the source directories.
 // This function attempts to create a meaningful relative path.
@@ -156,3 +156,63 @@ func TestCoberturaParser_Parse_UncoveredLines(t *testing.T) {
 		t.Errorf("UncoveredLines = %v, want %v", report.Files[0].UncoveredLines, want)
 	}
 }
+
+func TestCoberturaParser_Parse_CoveredLines(t *testing.T) {
+	xml := `<?xml version="1.0"?>
+<coverage>
+  <packages>
+    <package name="pkg">
+      <classes>
+        <class name="Test" filename="test.go">
+          <lines>
+            <line number="1" hits="1"/>
+            <line number="2" hits="0"/>
+            <line number="3" hits="5"/>
+          </lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>`
+	p := &CoberturaParser{}
+	report, err := p.Parse(strings.NewReader(xml))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	fc := report.Files[0]
+	if len(fc.CoveredLines) != 2 || fc.CoveredLines[0] != 1 || fc.CoveredLines[1] != 3 {
+		t.Errorf("CoveredLines = %v, want [1 3]", fc.CoveredLines)
+	}
+}
+
+func TestCoberturaParser_Parse_HitsAndBranches(t *testing.T) {
+	xml := `<?xml version="1.0"?>
+<coverage>
+  <packages>
+    <package name="pkg">
+      <classes>
+        <class name="Test" filename="test.go">
+          <lines>
+            <line number="1" hits="3"/>
+            <line number="2" hits="2" branch="true" condition-coverage="50% (1/2)"/>
+            <line number="3" hits="1" branch="true" condition-coverage="100% (2/2)"/>
+            <line number="4" hits="0" branch="true" condition-coverage="0% (0/2)"/>
+          </lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>`
+	p := &CoberturaParser{}
+	report, err := p.Parse(strings.NewReader(xml))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	fc := report.Files[0]
+	if fc.LineHits[1] != 3 || fc.LineHits[2] != 2 || fc.LineHits[4] != 0 {
+		t.Errorf("LineHits = %v", fc.LineHits)
+	}
+	if len(fc.PartialLines) != 1 || fc.PartialLines[0] != 2 {
+		t.Errorf("PartialLines = %v, want [2]", fc.PartialLines)
+	}
+}
@@ -20,6 +20,7 @@ func (p *LCOVParser) Parse(r io.Reader) (*coverage.Report, error) {
 	scanner := bufio.NewScanner(r)
 
 	var current *coverage.FileCoverage
+	var branches map[int]*branchCount
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -35,8 +36,10 @@ func (p *LCOVParser) Parse(r io.Reader) (*coverage.Report, error) {
 				filePath = filepath.Join(p.SourcePrefix, filePath)
 			}
 			current = &coverage.FileCoverage{
-				Path: filePath,
+				Path:     filePath,
+				LineHits: make(map[int]int),
 			}
+			branches = make(map[int]*branchCount)
 
 		case strings.HasPrefix(line, "DA:"):
 			if current == nil {
@@ -47,13 +50,36 @@ func (p *LCOVParser) Parse(r io.Reader) (*coverage.Report, error) {
 				lineNum, _ := strconv.Atoi(parts[0])
 				hits, _ := strconv.Atoi(parts[1])
 				current.LinesTotal++
+				current.LineHits[lineNum] = hits
 				if hits > 0 {
 					current.LinesCovered++
+					current.CoveredLines = append(current.CoveredLines, lineNum)
 				} else {
 					current.UncoveredLines = append(current.UncoveredLines, lineNum)
 				}
 			}
 
+		case strings.HasPrefix(line, "BRDA:"):
+			// BRDA:<line>,<block>,<branch>,<taken>, where taken is "-" when
+			// the block was never reached
+			if current == nil {
+				continue
+			}
+			parts := strings.Split(strings.TrimPrefix(line, "BRDA:"), ",")
+			if len(parts) >= 4 {
+				lineNum, _ := strconv.Atoi(parts[0])
+				taken, _ := strconv.Atoi(parts[3])
+				b := branches[lineNum]
+				if b == nil {
+					b = &branchCount{}
+					branches[lineNum] = b
+				}
+				b.total++
+				if taken > 0 {
+					b.taken++
+				}
+			}
+
 		case strings.HasPrefix(line, "LF:"):
 			if current != nil {
 				lf, _ := strconv.Atoi(strings.TrimPrefix(line, "LF:"))
@@ -72,6 +98,7 @@ func (p *LCOVParser) Parse(r io.Reader) (*coverage.Report, error) {
 
 		case line == "end_of_record":
 			if current != nil {
+				current.PartialLines = partialLines(current.LineHits, branches)
 				report.Files = append(report.Files, *current)
 				current = nil
 			}
@@ -165,3 +165,47 @@ end_of_record`
 		t.Fatalf("got %d files, want 1", len(report.Files))
 	}
 }
+
+func TestLCOVParser_Parse_CoveredLines(t *testing.T) {
+	lcov := "SF:a.go\nDA:1,3\nDA:2,0\nDA:3,1\nend_of_record\n"
+	p := &LCOVParser{}
+	report, err := p.Parse(strings.NewReader(lcov))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	fc := report.Files[0]
+	if len(fc.CoveredLines) != 2 || fc.CoveredLines[0] != 1 || fc.CoveredLines[1] != 3 {
+		t.Errorf("CoveredLines = %v, want [1 3]", fc.CoveredLines)
+	}
+	if len(fc.UncoveredLines) != 1 || fc.UncoveredLines[0] != 2 {
+		t.Errorf("UncoveredLines = %v, want [2]", fc.UncoveredLines)
+	}
+}
+
+func TestLCOVParser_Parse_HitsAndBranches(t *testing.T) {
+	input := `SF:test.go
+DA:1,4
+DA:2,2
+DA:3,1
+DA:4,0
+BRDA:2,0,0,1
+BRDA:2,0,1,0
+BRDA:3,0,0,1
+BRDA:3,0,1,2
+BRDA:4,0,0,-
+BRDA:4,0,1,-
+end_of_record
+`
+	p := &LCOVParser{}
+	report, err := p.Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	fc := report.Files[0]
+	if fc.LineHits[1] != 4 || fc.LineHits[3] != 1 || fc.LineHits[4] != 0 {
+		t.Errorf("LineHits = %v", fc.LineHits)
+	}
+	if len(fc.PartialLines) != 1 || fc.PartialLines[0] != 2 {
+		t.Errorf("PartialLines = %v, want [2]", fc.PartialLines)
+	}
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"io"
+	"sort"
 
 	"github.com/manashmandal/litecov/internal/coverage"
 )
@@ -9,3 +10,22 @@ import (
 type Parser interface {
 	Parse(r io.Reader) (*coverage.Report, error)
 }
+
+// branchCount tracks how many branches of a line were taken.
+type branchCount struct {
+	taken int
+	total int
+}
+
+// partialLines returns the executed lines, in order, with some but not all
+// of their branches taken.
+func partialLines(hits map[int]int, branches map[int]*branchCount) []int {
+	var lines []int
+	for line, b := range branches {
+		if hits[line] > 0 && b.taken < b.total {
+			lines = append(lines, line)
+		}
+	}
+	sort.Ints(lines)
+	return lines
+}
@@ -0,0 +1,159 @@
+package paths
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// generatedHeaderLines is how many lines from the top of a file are scanned
+// for generated-code markers.
+const generatedHeaderLines = 50
+
+// goGeneratedRegex matches the standard Go generated-code comment.
+// See https://go.dev/s/generatedcode.
+var goGeneratedRegex = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+// commentLineRegex matches lines that start with a comment, in any of the
+// comment styles of the supported languages.
+var commentLineRegex = regexp.MustCompile(`^(//|#|/\*|\*|--|;|<!--)`)
+
+// generatedTagRegex matches an "@generated" tag, but not a longer word such
+// as "@generatedBy".
+var generatedTagRegex = regexp.MustCompile(`@generated\b`)
+
+// IsGeneratedSource reports whether the content starts with a generated-code
+// marker: the Go "// Code generated ... DO NOT EDIT." header or an
+// "@generated" tag on a comment line. Tags in code or string literals do not
+// count.
+func IsGeneratedSource(r io.Reader) bool {
+	scanner := bufio.NewScanner(r)
+	for i := 0; i < generatedHeaderLines && scanner.Scan(); i++ {
+		line := strings.TrimSpace(scanner.Text())
+		if goGeneratedRegex.MatchString(line) {
+			return true
+		}
+		if commentLineRegex.MatchString(line) && generatedTagRegex.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// gitAttribute is a single linguist-generated rule from .gitattributes.
+type gitAttribute struct {
+	pattern   string
+	generated bool
+}
+
+// parseGitAttributes extracts linguist-generated rules from .gitattributes
+// content. Patterns are converted to the glob syntax used by MatchGlob.
+func parseGitAttributes(r io.Reader) []gitAttribute {
+	var attrs []gitAttribute
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		for _, attr := range fields[1:] {
+			var generated bool
+			switch attr {
+			case "linguist-generated", "linguist-generated=true":
+				generated = true
+			case "-linguist-generated", "!linguist-generated", "linguist-generated=false":
+				generated = false
+			default:
+				continue
+			}
+			attrs = append(attrs, gitAttribute{
+				pattern:   gitAttributesGlob(fields[0]),
+				generated: generated,
+			})
+		}
+	}
+	return attrs
+}
+
+// gitAttributesGlob converts a .gitattributes pattern to a MatchGlob pattern.
+// Patterns without a slash match at any depth; a leading slash anchors the
+// pattern to the repository root.
+func gitAttributesGlob(pattern string) string {
+	if strings.HasPrefix(pattern, "/") {
+		return strings.TrimPrefix(pattern, "/")
+	}
+	if !strings.Contains(pattern, "/") {
+		return "**/" + pattern
+	}
+	return pattern
+}
+
+// GeneratedDetector detects generated files in a checkout from their headers
+// and from linguist-generated attributes in the root .gitattributes file.
+type GeneratedDetector struct {
+	Root  string
+	attrs []gitAttribute
+	cache map[string]bool
+}
+
+// NewGeneratedDetector creates a detector for the checkout at root.
+// A missing .gitattributes file is not an error.
+func NewGeneratedDetector(root string) *GeneratedDetector {
+	d := &GeneratedDetector{
+		Root:  root,
+		cache: make(map[string]bool),
+	}
+	if f, err := os.Open(filepath.Join(root, ".gitattributes")); err == nil {
+		d.attrs = parseGitAttributes(f)
+		f.Close()
+	}
+	return d
+}
+
+// IsGenerated reports whether the file at the repo-relative or coverage path
+// is generated. Files that cannot be read are only checked against
+// .gitattributes.
+func (d *GeneratedDetector) IsGenerated(path string) bool {
+	if d == nil {
+		return false
+	}
+	if generated, ok := d.cache[path]; ok {
+		return generated
+	}
+
+	generated, matched := d.attributeGenerated(path)
+	if !matched {
+		// Coverage paths may carry a module or workspace prefix, so also
+		// match .gitattributes against the repo-relative form.
+		if normalized := NormalizePathForAnnotation(path); normalized != path {
+			generated, matched = d.attributeGenerated(normalized)
+		}
+	}
+	if !matched {
+		if f, err := OpenSource(d.Root, path); err == nil {
+			generated = IsGeneratedSource(f)
+			f.Close()
+		}
+	}
+	d.cache[path] = generated
+	return generated
+}
+
+// attributeGenerated returns the linguist-generated value of the last matching
+// .gitattributes rule, and whether any rule matched.
+func (d *GeneratedDetector) attributeGenerated(path string) (generated, matched bool) {
+	for _, attr := range d.attrs {
+		if MatchGlob(attr.pattern, path) || MatchGlob(attr.pattern+"/**", path) {
+			generated = attr.generated
+			matched = true
+		}
+	}
+	return generated, matched
+}
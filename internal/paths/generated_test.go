@@ -0,0 +1,129 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsGeneratedSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected bool
+	}{
+		{"go header", "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n", true},
+		{"go header after license", "// Copyright 2026\n\n// Code generated by mockgen. DO NOT EDIT.\npackage mocks\n", true},
+		{"at generated js", "/**\n * @generated\n */\nexport const x = 1;\n", true},
+		{"at generated python", "# @generated by codegen\nx = 1\n", true},
+		{"handwritten", "package handler\n\nfunc Handle() {}\n", false},
+		{"go header without period", "// Code generated by hand, DO NOT EDIT\npackage x\n", false},
+		{"go header in a string literal", "package x\n\nconst header = \"// Code generated by x. DO NOT EDIT.\"\n", false},
+		{"at generated in a string literal", "package x\n\nconst tag = \"@generated\"\n", false},
+		{"at generated in code", "const marker = '@generated';\n", false},
+		{"at generated in a block comment", "/* This file is @generated by thrift */\nint x;\n", true},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsGeneratedSource(strings.NewReader(tt.content)); got != tt.expected {
+				t.Errorf("IsGeneratedSource() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseGitAttributes(t *testing.T) {
+	content := `# comment
+*.pb.go linguist-generated=true
+/api/openapi.go linguist-generated
+docs/gen/** linguist-generated
+keep/*.go -linguist-generated
+*.md text eol=lf
+`
+	attrs := parseGitAttributes(strings.NewReader(content))
+	want := []gitAttribute{
+		{pattern: "**/*.pb.go", generated: true},
+		{pattern: "api/openapi.go", generated: true},
+		{pattern: "docs/gen/**", generated: true},
+		{pattern: "keep/*.go", generated: false},
+	}
+	if len(attrs) != len(want) {
+		t.Fatalf("got %d attributes, want %d: %v", len(attrs), len(want), attrs)
+	}
+	for i := range want {
+		if attrs[i] != want[i] {
+			t.Errorf("attrs[%d] = %v, want %v", i, attrs[i], want[i])
+		}
+	}
+}
+
+func writeFile(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGeneratedDetector_IsGenerated(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, ".gitattributes", "internal/schema/** linguist-generated\ninternal/keep/gen.go linguist-generated=false\n")
+	writeFile(t, root, "internal/generatedreports/handler.go", "package generatedreports\n")
+	writeFile(t, root, "internal/mocks/mock_service.go", "// Code generated by MockGen. DO NOT EDIT.\npackage mocks\n")
+	writeFile(t, root, "internal/schema/types.go", "package schema\n")
+	writeFile(t, root, "internal/keep/gen.go", "// Code generated by hand. DO NOT EDIT.\npackage keep\n")
+
+	d := NewGeneratedDetector(root)
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"internal/generatedreports/handler.go", false},
+		{"internal/mocks/mock_service.go", true},
+		{"github.com/user/repo/internal/mocks/mock_service.go", true},
+		{"internal/schema/types.go", true},
+		{"internal/schema/missing.go", true},
+		{"internal/keep/gen.go", false},
+		{"internal/missing.go", false},
+		{filepath.ToSlash(filepath.Join(root, "internal", "mocks", "mock_service.go")), true},
+		{"/home/runner/work/repo/repo/internal/mocks/mock_service.go", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := d.IsGenerated(tt.path); got != tt.expected {
+				t.Errorf("IsGenerated(%q) = %v, want %v", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGeneratedDetector_Nil(t *testing.T) {
+	var d *GeneratedDetector
+	if d.IsGenerated("any.go") {
+		t.Error("nil detector should not report generated files")
+	}
+}
+
+func TestFilter_Generated(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "internal/api/client.go", "// Code generated by oapi-codegen. DO NOT EDIT.\npackage api\n")
+	writeFile(t, root, "internal/api/handler.go", "package api\n")
+
+	f := NewFilter(nil, nil, true)
+	f.Generated = NewGeneratedDetector(root)
+
+	if f.IsSourceFile("internal/api/client.go") {
+		t.Error("generated file should not be a source file")
+	}
+	if !f.IsSourceFile("internal/api/handler.go") {
+		t.Error("handwritten file should be a source file")
+	}
+}
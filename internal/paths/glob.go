@@ -0,0 +1,211 @@
+package paths
+
+import (
+	"path"
+	"strings"
+)
+
+// DefaultExcludes are the exclude patterns applied when the user does not
+// disable them. They cover vendored dependencies and well-known generated
+// file names; other generated files are found by GeneratedDetector.
+var DefaultExcludes = []string{
+	"vendor/**",
+	"**/vendor/**",
+	"**/*.pb.go",
+	"**/*_mock.go",
+}
+
+// Filter selects files by include and exclude glob patterns.
+// Patterns use doublestar syntax: "*" matches within a path segment,
+// "**" matches any number of segments, and "{a,b}" matches alternatives.
+type Filter struct {
+	Include []string
+	Exclude []string
+	// Generated, if set, excludes files detected as generated code.
+	Generated *GeneratedDetector
+	// Languages classifies source files. DefaultLanguages are used if nil.
+	Languages *Registry
+}
+
+// NewFilter creates a filter from user patterns. When useDefaults is true,
+// DefaultExcludes are applied in addition to the given exclude patterns.
+func NewFilter(include, exclude []string, useDefaults bool) *Filter {
+	f := &Filter{Include: include}
+	if useDefaults {
+		f.Exclude = append(f.Exclude, DefaultExcludes...)
+	}
+	f.Exclude = append(f.Exclude, exclude...)
+	return f
+}
+
+// Match reports whether path passes the filter: it must match at least one
+// include pattern (if any are set) and no exclude pattern. Paths are matched
+// both as given and in their repo-relative form.
+func (f *Filter) Match(p string) bool {
+	if f == nil {
+		return true
+	}
+	candidates := []string{p}
+	if normalized := NormalizePathForAnnotation(p); normalized != p {
+		candidates = append(candidates, normalized)
+	}
+	if len(f.Include) > 0 && !matchAny(f.Include, candidates) {
+		return false
+	}
+	if matchAny(f.Exclude, candidates) {
+		return false
+	}
+	return !f.Generated.IsGenerated(p)
+}
+
+// IsSourceFile reports whether path is a source file that passes the filter.
+// A nil filter falls back to the package-level IsSourceFile and its default
+// excludes.
+func (f *Filter) IsSourceFile(p string) bool {
+	if f == nil {
+		return IsSourceFile(p)
+	}
+	languages := f.Languages
+	if languages == nil {
+		languages = defaultRegistry
+	}
+	return languages.IsSourceFile(p) && f.Match(p)
+}
+
+// FilterPaths returns the paths that pass the filter.
+func (f *Filter) FilterPaths(files []string) []string {
+	if f == nil {
+		return files
+	}
+	var result []string
+	for _, p := range files {
+		if f.Match(p) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+func matchAny(patterns, candidates []string) bool {
+	for _, pattern := range patterns {
+		for _, c := range candidates {
+			if MatchGlob(pattern, c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MatchGlob reports whether name matches the doublestar glob pattern.
+// Invalid patterns never match.
+func MatchGlob(pattern, name string) bool {
+	pattern = strings.TrimPrefix(pattern, "./")
+	name = strings.TrimPrefix(name, "./")
+	for _, expanded := range expandBraces(pattern) {
+		if matchSegments(strings.Split(expanded, "/"), strings.Split(name, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Collapse repeated "**" and try every possible split point
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		ok, err := path.Match(pattern[0], name[0])
+		if err != nil || !ok {
+			return false
+		}
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+	return len(name) == 0
+}
+
+// expandBraces expands "{a,b}" alternatives into separate patterns.
+func expandBraces(pattern string) []string {
+	start := strings.Index(pattern, "{")
+	if start < 0 {
+		return []string{pattern}
+	}
+	depth := 0
+	end := -1
+	var options []string
+	last := start + 1
+	for i := start; i < len(pattern) && end < 0; i++ {
+		switch pattern[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				options = append(options, pattern[last:i])
+				end = i
+			}
+		case ',':
+			if depth == 1 {
+				options = append(options, pattern[last:i])
+				last = i + 1
+			}
+		}
+	}
+	if end < 0 {
+		return []string{pattern}
+	}
+
+	var result []string
+	for _, opt := range options {
+		result = append(result, expandBraces(pattern[:start]+opt+pattern[end+1:])...)
+	}
+	return result
+}
+
+// SplitPatterns splits a comma- or newline-separated list of glob patterns.
+// Commas inside "{...}" alternatives are preserved.
+func SplitPatterns(s string) []string {
+	var result []string
+	depth := 0
+	last := 0
+	flush := func(i int) {
+		if p := strings.TrimSpace(s[last:i]); p != "" {
+			result = append(result, p)
+		}
+		last = i + 1
+	}
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				flush(i)
+			}
+		case '\n':
+			flush(i)
+		}
+	}
+	flush(len(s))
+	return result
+}
@@ -0,0 +1,150 @@
+package paths
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "cmd/app/main.go", true},
+		{"internal/billing/**", "internal/billing/invoice.go", true},
+		{"internal/billing/**", "internal/billing/tax/rate.go", true},
+		{"internal/billing/**", "internal/billingx/rate.go", false},
+		{"**/testdata/**", "internal/parser/testdata/a.go", true},
+		{"**/migrations/*.py", "app/migrations/0001_init.py", true},
+		{"**/*.{pb,gen}.go", "api/v1/types.pb.go", true},
+		{"**/*.{pb,gen}.go", "api/v1/types.gen.go", true},
+		{"**/*.{pb,gen}.go", "api/v1/types.go", false},
+		{"cmd/?/main.go", "cmd/a/main.go", true},
+		{"./cmd/**", "cmd/app/main.go", true},
+		{"[", "[", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"_"+tt.name, func(t *testing.T) {
+			if got := MatchGlob(tt.pattern, tt.name); got != tt.expected {
+				t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilter_DefaultExcludes(t *testing.T) {
+	f := NewFilter(nil, nil, true)
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"cmd/app/main.go", true},
+		{"vendor/github.com/pkg/errors/errors.go", false},
+		{"internal/vendor/code.go", false},
+		{"internal/generatedreports/handler.go", true},
+		{"internal/mocks/mock_service.go", true},
+		{"api/v1/types.pb.go", false},
+		{"internal/test/service_mock.go", false},
+		{"github.com/user/repo/vendor/a/b.go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := f.Match(tt.path); got != tt.expected {
+				t.Errorf("Match(%q) = %v, want %v", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilter_WithoutDefaults(t *testing.T) {
+	f := NewFilter(nil, []string{"**/migrations/**"}, false)
+
+	if !f.Match("vendor/github.com/pkg/errors/errors.go") {
+		t.Error("vendor file should match when defaults are disabled")
+	}
+	if f.Match("app/migrations/0001_init.py") {
+		t.Error("migration should be excluded")
+	}
+}
+
+func TestFilter_Include(t *testing.T) {
+	f := NewFilter([]string{"internal/**"}, nil, false)
+
+	tests := []struct {
+		path     string
+		expected bool
+	}{
+		{"internal/foo/a.go", true},
+		{"github.com/user/repo/internal/foo/a.go", true},
+		{"cmd/app/main.go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := f.Match(tt.path); got != tt.expected {
+				t.Errorf("Match(%q) = %v, want %v", tt.path, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFilter_Nil(t *testing.T) {
+	var f *Filter
+	if !f.Match("anything.go") {
+		t.Error("nil filter should match everything")
+	}
+	files := []string{"a.go", "b.go"}
+	if got := f.FilterPaths(files); !reflect.DeepEqual(got, files) {
+		t.Errorf("FilterPaths() = %v, want %v", got, files)
+	}
+}
+
+func TestFilter_IsSourceFile(t *testing.T) {
+	var nilFilter *Filter
+	if nilFilter.IsSourceFile("api/v1/types.pb.go") {
+		t.Error("nil filter should apply the default excludes")
+	}
+	if !nilFilter.IsSourceFile("internal/generatedreports/handler.go") {
+		t.Error("nil filter should not guess generated files from their names")
+	}
+	if !NewFilter(nil, nil, false).IsSourceFile("api/v1/types.pb.go") {
+		t.Error("filter without defaults should keep generated file names")
+	}
+}
+
+func TestFilter_FilterPaths(t *testing.T) {
+	f := NewFilter(nil, []string{"**/testdata/**"}, true)
+	got := f.FilterPaths([]string{"cmd/main.go", "internal/testdata/x.go", "vendor/a/b.go"})
+	want := []string{"cmd/main.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FilterPaths() = %v, want %v", got, want)
+	}
+}
+
+func TestSplitPatterns(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"", nil},
+		{"a/**", []string{"a/**"}},
+		{"a/**, b/**", []string{"a/**", "b/**"}},
+		{"a/**\nb/**\n", []string{"a/**", "b/**"}},
+		{"**/*.{pb,gen}.go,cmd/**", []string{"**/*.{pb,gen}.go", "cmd/**"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := SplitPatterns(tt.input); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("SplitPatterns(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
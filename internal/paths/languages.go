@@ -0,0 +1,224 @@
+package paths
+
+import (
+	"encoding/json"
+	"io"
+	"path"
+	"strings"
+)
+
+// Language describes the file conventions of a programming language, used to
+// decide which changed files are source files that should have coverage.
+type Language struct {
+	Name       string   `json:"name"`
+	Extensions []string `json:"extensions"`
+	// TestPatterns are glob patterns matched against the file name
+	// (e.g. "*_test.go", "*.spec.ts").
+	TestPatterns []string `json:"test_patterns,omitempty"`
+	// TestDirs are directory names (globs allowed) that only contain tests.
+	TestDirs []string `json:"test_dirs,omitempty"`
+	// VendorDirs are directory names that contain third-party or build output.
+	VendorDirs []string `json:"vendor_dirs,omitempty"`
+	// IgnorePatterns are file name globs that are neither tests nor source
+	// that should have coverage (e.g. "setup.py", "*.d.ts").
+	IgnorePatterns []string `json:"ignore_patterns,omitempty"`
+}
+
+// DefaultLanguages are the languages known to litecov out of the box.
+var DefaultLanguages = []Language{
+	{
+		Name:         "go",
+		Extensions:   []string{".go"},
+		TestPatterns: []string{"*_test.go"},
+		TestDirs:     []string{"testdata"},
+	},
+	{
+		Name:           "python",
+		Extensions:     []string{".py"},
+		TestPatterns:   []string{"test_*.py", "*_test.py", "conftest.py"},
+		TestDirs:       []string{"tests"},
+		VendorDirs:     []string{"__pycache__", "venv", ".venv", "site-packages", ".tox"},
+		IgnorePatterns: []string{"setup.py"},
+	},
+	{
+		Name:           "javascript",
+		Extensions:     []string{".js", ".jsx", ".mjs", ".cjs"},
+		TestPatterns:   []string{"*.test.js", "*.spec.js", "*.test.jsx", "*.spec.jsx", "*.test.mjs", "*.spec.mjs"},
+		TestDirs:       []string{"__tests__", "__mocks__", "test", "tests"},
+		VendorDirs:     []string{"node_modules", "bower_components", "dist", "build", "coverage"},
+		IgnorePatterns: []string{"*.config.js", "*.config.mjs", "*.config.cjs", "*.min.js"},
+	},
+	{
+		Name:           "typescript",
+		Extensions:     []string{".ts", ".tsx", ".mts", ".cts"},
+		TestPatterns:   []string{"*.test.ts", "*.spec.ts", "*.test.tsx", "*.spec.tsx"},
+		TestDirs:       []string{"__tests__", "__mocks__", "test", "tests"},
+		VendorDirs:     []string{"node_modules", "dist", "build", "coverage"},
+		IgnorePatterns: []string{"*.d.ts", "*.config.ts"},
+	},
+	{
+		Name:         "java",
+		Extensions:   []string{".java"},
+		TestPatterns: []string{"*Test.java", "*Tests.java", "Test*.java", "*IT.java"},
+		TestDirs:     []string{"test"},
+		VendorDirs:   []string{"target", "build", ".gradle"},
+	},
+	{
+		Name:         "kotlin",
+		Extensions:   []string{".kt"},
+		TestPatterns: []string{"*Test.kt", "*Tests.kt"},
+		TestDirs:     []string{"test", "androidTest"},
+		VendorDirs:   []string{"target", "build", ".gradle"},
+	},
+	{
+		Name:       "rust",
+		Extensions: []string{".rs"},
+		TestDirs:   []string{"tests", "benches"},
+		VendorDirs: []string{"target"},
+	},
+	{
+		Name:         "ruby",
+		Extensions:   []string{".rb"},
+		TestPatterns: []string{"*_spec.rb", "*_test.rb", "test_*.rb"},
+		TestDirs:     []string{"spec", "test"},
+		VendorDirs:   []string{"vendor", ".bundle"},
+	},
+	{
+		Name:           "csharp",
+		Extensions:     []string{".cs"},
+		TestPatterns:   []string{"*Test.cs", "*Tests.cs"},
+		TestDirs:       []string{"*.Tests", "*.Test"},
+		VendorDirs:     []string{"bin", "obj", "packages"},
+		IgnorePatterns: []string{"*.Designer.cs", "*.g.cs", "AssemblyInfo.cs"},
+	},
+	{
+		Name:         "php",
+		Extensions:   []string{".php"},
+		TestPatterns: []string{"*Test.php"},
+		TestDirs:     []string{"tests"},
+		VendorDirs:   []string{"vendor"},
+	},
+	{
+		Name:         "c",
+		Extensions:   []string{".c", ".h"},
+		TestPatterns: []string{"*_test.c", "test_*.c"},
+		TestDirs:     []string{"test", "tests"},
+		VendorDirs:   []string{"build", "third_party", "external"},
+	},
+	{
+		Name:         "cpp",
+		Extensions:   []string{".cc", ".cpp", ".cxx", ".hh", ".hpp", ".hxx"},
+		TestPatterns: []string{"*_test.cc", "*_test.cpp", "*_unittest.cc", "*_unittest.cpp", "test_*.cpp"},
+		TestDirs:     []string{"test", "tests"},
+		VendorDirs:   []string{"build", "third_party", "external"},
+	},
+	{
+		Name:         "swift",
+		Extensions:   []string{".swift"},
+		TestPatterns: []string{"*Tests.swift", "*Test.swift"},
+		TestDirs:     []string{"Tests", "*Tests"},
+		VendorDirs:   []string{".build", "Pods", "Carthage"},
+	},
+}
+
+// Registry maps file extensions to languages.
+type Registry struct {
+	languages []Language
+	byExt     map[string]int
+}
+
+// NewRegistry creates a registry containing the given languages.
+func NewRegistry(languages ...Language) *Registry {
+	r := &Registry{byExt: make(map[string]int)}
+	r.Register(languages...)
+	return r
+}
+
+// DefaultRegistry returns a new registry containing DefaultLanguages.
+func DefaultRegistry() *Registry {
+	return NewRegistry(DefaultLanguages...)
+}
+
+// defaultRegistry backs the package-level IsSourceFile.
+var defaultRegistry = DefaultRegistry()
+
+// Register adds languages to the registry. A language with the same name as
+// an existing one replaces it, and extensions are remapped to the newest
+// language that claims them.
+func (r *Registry) Register(languages ...Language) {
+	for _, lang := range languages {
+		idx := -1
+		for i := range r.languages {
+			if r.languages[i].Name == lang.Name {
+				idx = i
+				break
+			}
+		}
+		if idx >= 0 {
+			r.languages[idx] = lang
+		} else {
+			idx = len(r.languages)
+			r.languages = append(r.languages, lang)
+		}
+		for _, ext := range lang.Extensions {
+			r.byExt[strings.ToLower(ext)] = idx
+		}
+	}
+}
+
+// Lookup returns the language for the file's extension.
+func (r *Registry) Lookup(file string) (Language, bool) {
+	idx, ok := r.byExt[strings.ToLower(path.Ext(file))]
+	if !ok {
+		return Language{}, false
+	}
+	lang := r.languages[idx]
+	// The extension may have been claimed by a replacement language
+	// that no longer lists it.
+	for _, ext := range lang.Extensions {
+		if strings.EqualFold(ext, path.Ext(file)) {
+			return lang, true
+		}
+	}
+	return Language{}, false
+}
+
+// IsSourceFile reports whether file is a source file of a known language
+// that is not a test, vendored or ignored file.
+func (r *Registry) IsSourceFile(file string) bool {
+	lang, ok := r.Lookup(file)
+	if !ok {
+		return false
+	}
+
+	base := path.Base(file)
+	if matchName(lang.TestPatterns, base) || matchName(lang.IgnorePatterns, base) {
+		return false
+	}
+
+	dirs := strings.Split(path.Dir(file), "/")
+	for _, dir := range dirs {
+		if matchName(lang.TestDirs, dir) || matchName(lang.VendorDirs, dir) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchName(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadLanguages reads a JSON array of Language definitions.
+func LoadLanguages(r io.Reader) ([]Language, error) {
+	var languages []Language
+	if err := json.NewDecoder(r).Decode(&languages); err != nil {
+		return nil, err
+	}
+	return languages, nil
+}
@@ -0,0 +1,104 @@
+package paths
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistry_Lookup(t *testing.T) {
+	r := DefaultRegistry()
+
+	tests := []struct {
+		path     string
+		expected string
+		found    bool
+	}{
+		{"cmd/main.go", "go", true},
+		{"src/app.TS", "typescript", true},
+		{"src/lib.rs", "rust", true},
+		{"README.md", "", false},
+		{"Makefile", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			lang, ok := r.Lookup(tt.path)
+			if ok != tt.found || lang.Name != tt.expected {
+				t.Errorf("Lookup(%q) = %q, %v, want %q, %v", tt.path, lang.Name, ok, tt.expected, tt.found)
+			}
+		})
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := DefaultRegistry()
+	r.Register(Language{
+		Name:         "elixir",
+		Extensions:   []string{".ex"},
+		TestPatterns: []string{"*_test.exs"},
+		VendorDirs:   []string{"deps", "_build"},
+	})
+
+	if !r.IsSourceFile("lib/app/worker.ex") {
+		t.Error("registered language should be recognized")
+	}
+	if r.IsSourceFile("deps/plug/lib/plug.ex") {
+		t.Error("vendor dir of registered language should be skipped")
+	}
+}
+
+func TestRegistry_RegisterReplaces(t *testing.T) {
+	r := DefaultRegistry()
+	r.Register(Language{
+		Name:       "go",
+		Extensions: []string{".go"},
+	})
+
+	if !r.IsSourceFile("internal/foo_test.go") {
+		t.Error("replacement language without test patterns should accept test files")
+	}
+}
+
+func TestRegistry_RegisterReplacesExtensions(t *testing.T) {
+	r := DefaultRegistry()
+	r.Register(Language{
+		Name:       "c",
+		Extensions: []string{".c"},
+	})
+
+	if r.IsSourceFile("include/parser.h") {
+		t.Error("extension dropped by replacement language should not be recognized")
+	}
+}
+
+func TestLoadLanguages(t *testing.T) {
+	input := `[{"name": "elixir", "extensions": [".ex"], "test_patterns": ["*_test.exs"], "vendor_dirs": ["deps"]}]`
+	languages, err := LoadLanguages(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("LoadLanguages() error = %v", err)
+	}
+	if len(languages) != 1 {
+		t.Fatalf("got %d languages, want 1", len(languages))
+	}
+	if languages[0].Name != "elixir" || languages[0].VendorDirs[0] != "deps" {
+		t.Errorf("unexpected language: %+v", languages[0])
+	}
+}
+
+func TestLoadLanguages_Invalid(t *testing.T) {
+	if _, err := LoadLanguages(strings.NewReader("not json")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestFilter_Languages(t *testing.T) {
+	f := NewFilter(nil, nil, true)
+	f.Languages = NewRegistry(Language{Name: "elixir", Extensions: []string{".ex"}})
+
+	if !f.IsSourceFile("lib/app.ex") {
+		t.Error("custom registry language should be a source file")
+	}
+	if f.IsSourceFile("cmd/main.go") {
+		t.Error("language missing from custom registry should not be a source file")
+	}
+}
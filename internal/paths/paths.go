@@ -2,79 +2,18 @@
 package paths
 
 import (
+	"os"
 	"path/filepath"
 	"strings"
 )
 
-// IsSourceFile checks if a file is a source file that should have coverage.
-// Supports Go and Python files, excludes test files, vendor directories, and generated files.
+// IsSourceFile checks if a file is a source file that should have coverage,
+// using the conventions of DefaultLanguages. Test files, vendored directories,
+// build output and DefaultExcludes are excluded. Use a Filter to choose the
+// excludes instead, and a GeneratedDetector to find generated code.
 func IsSourceFile(path string) bool {
-	// Go files
-	if strings.HasSuffix(path, ".go") {
-		return isGoSourceFile(path)
-	}
-
-	// Python files
-	if strings.HasSuffix(path, ".py") {
-		return isPythonSourceFile(path)
-	}
-
-	return false
-}
-
-// isGoSourceFile checks if a Go file is a source file (not test/vendor/generated).
-func isGoSourceFile(path string) bool {
-	// Skip test files
-	if strings.HasSuffix(path, "_test.go") {
-		return false
-	}
-	// Skip vendor directory
-	if strings.HasPrefix(path, "vendor/") || strings.Contains(path, "/vendor/") {
-		return false
-	}
-	// Skip generated files (common patterns)
-	if strings.Contains(path, "generated") ||
-		strings.HasSuffix(path, ".pb.go") ||
-		strings.HasSuffix(path, "_mock.go") ||
-		strings.Contains(path, "mock_") {
-		return false
-	}
-	return true
-}
-
-// isPythonSourceFile checks if a Python file is a source file (not test/cache/config).
-func isPythonSourceFile(path string) bool {
-	base := filepath.Base(path)
-
-	// Skip __pycache__ directories
-	if strings.Contains(path, "__pycache__") {
-		return false
-	}
-	// Skip test files
-	if strings.HasSuffix(path, "_test.py") ||
-		strings.HasPrefix(base, "test_") {
-		return false
-	}
-	// Skip pytest configuration
-	if base == "conftest.py" {
-		return false
-	}
-	// Skip setup files
-	if base == "setup.py" {
-		return false
-	}
-	// Skip virtualenv/venv directories
-	if strings.Contains(path, "/venv/") ||
-		strings.Contains(path, "/.venv/") ||
-		strings.HasPrefix(path, "venv/") ||
-		strings.HasPrefix(path, ".venv/") {
-		return false
-	}
-	// Skip common non-source Python files
-	if strings.Contains(path, "/site-packages/") {
-		return false
-	}
-	return true
+	return defaultRegistry.IsSourceFile(path) &&
+		!matchAny(DefaultExcludes, []string{path, NormalizePathForAnnotation(path)})
 }
 
 // FindMatchingChangedFile returns the matching changed file path, or empty string if not found.
@@ -133,3 +72,36 @@ func NormalizePathForAnnotation(path string) string {
 	}
 	return path
 }
+
+// OpenSource opens a file from the checkout at root. Absolute coverage paths
+// are tried as given first, since they usually point into the workspace.
+// Otherwise, or when the absolute path does not exist on this machine, the
+// path is tried relative to root and then in its repo-relative form, as
+// coverage paths may carry a module or workspace prefix.
+func OpenSource(root, path string) (*os.File, error) {
+	var firstErr error
+	for _, name := range sourceCandidates(root, path) {
+		f, err := os.Open(name)
+		if err == nil {
+			return f, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return nil, firstErr
+}
+
+// sourceCandidates returns the file names OpenSource tries, in order.
+func sourceCandidates(root, path string) []string {
+	native := filepath.FromSlash(path)
+	var names []string
+	if filepath.IsAbs(native) {
+		names = append(names, native)
+	}
+	names = append(names, filepath.Join(root, native))
+	if normalized := NormalizePathForAnnotation(path); normalized != path {
+		names = append(names, filepath.Join(root, filepath.FromSlash(normalized)))
+	}
+	return names
+}
@@ -1,6 +1,10 @@
 package paths
 
-import "testing"
+import (
+	"io"
+	"path/filepath"
+	"testing"
+)
 
 func TestIsSourceFile(t *testing.T) {
 	tests := []struct {
@@ -17,9 +21,8 @@ func TestIsSourceFile(t *testing.T) {
 		{"README.md", false},
 		{"vendor/github.com/pkg/errors/errors.go", false},
 		{"internal/vendor/code.go", false},
-		{"internal/generated/code.go", false},
+		{"internal/generatedreports/handler.go", true},
 		{"api/v1/types.pb.go", false},
-		{"internal/mocks/mock_service.go", false},
 		{"internal/test/service_mock.go", false},
 
 		// Python files
@@ -37,6 +40,40 @@ func TestIsSourceFile(t *testing.T) {
 		{"venv/lib/python3.9/site-packages/pkg.py", false},
 		{".venv/lib/python3.9/site-packages/pkg.py", false},
 
+		// JavaScript and TypeScript files
+		{"src/app.ts", true},
+		{"src/components/Button.tsx", true},
+		{"src/app.spec.ts", false},
+		{"src/components/Button.test.tsx", false},
+		{"src/__tests__/app.ts", false},
+		{"src/types.d.ts", false},
+		{"node_modules/lodash/index.js", false},
+		{"lib/index.js", true},
+
+		// JVM files
+		{"src/main/java/com/example/Service.java", true},
+		{"src/test/java/com/example/ServiceTest.java", false},
+		{"target/generated-sources/Foo.java", false},
+		{"app/src/main/kotlin/Main.kt", true},
+		{"app/src/main/kotlin/MainTest.kt", false},
+
+		// Other languages
+		{"src/lib.rs", true},
+		{"tests/integration.rs", false},
+		{"target/debug/build/foo.rs", false},
+		{"lib/user.rb", true},
+		{"spec/models/user_spec.rb", false},
+		{"src/Service.cs", true},
+		{"src/Service.Tests/ServiceTests.cs", false},
+		{"obj/Debug/AssemblyInfo.cs", false},
+		{"src/Controller.php", true},
+		{"vendor/laravel/framework/src/App.php", false},
+		{"src/parser.c", true},
+		{"src/parser.cpp", true},
+		{"src/parser_test.cc", false},
+		{"Sources/App/Model.swift", true},
+		{"Tests/AppTests/ModelTests.swift", false},
+
 		// Non-source files
 		{".github/workflows/ci.yml", false},
 		{"README.md", false},
@@ -144,3 +181,42 @@ func TestNormalizePathForAnnotation(t *testing.T) {
 		})
 	}
 }
+
+func TestOpenSource(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "internal/foo.go", "workspace\n")
+	elsewhere := t.TempDir()
+	writeFile(t, elsewhere, "internal/foo.go", "absolute\n")
+
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{"relative", "internal/foo.go", "workspace\n"},
+		{"module path", "github.com/user/repo/internal/foo.go", "workspace\n"},
+		{"existing absolute path", filepath.ToSlash(filepath.Join(elsewhere, "internal", "foo.go")), "absolute\n"},
+		{"absolute path from another machine", "/home/runner/work/repo/repo/internal/foo.go", "workspace\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := OpenSource(root, tt.path)
+			if err != nil {
+				t.Fatalf("OpenSource(%q) error: %v", tt.path, err)
+			}
+			defer f.Close()
+			data, err := io.ReadAll(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.expected {
+				t.Errorf("OpenSource(%q) read %q, want %q", tt.path, data, tt.expected)
+			}
+		})
+	}
+
+	if _, err := OpenSource(root, "internal/missing.go"); err == nil {
+		t.Error("OpenSource should fail for a missing file")
+	}
+}
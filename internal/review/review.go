@@ -0,0 +1,190 @@
+// Package review plans the pull request review comments posted on uncovered
+// added lines.
+package review
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/manashmandal/litecov/internal/comment"
+	"github.com/manashmandal/litecov/internal/coverage"
+	"github.com/manashmandal/litecov/internal/github"
+)
+
+// Marker identifies review comments posted by litecov.
+const Marker = "<!-- litecov:review -->"
+
+// DefaultMaxComments is the default cap on comments in a single review.
+const DefaultMaxComments = 20
+
+// resolvedText replaces the body of comments whose lines are now covered or
+// no longer part of the diff, whose threads are then resolved.
+const resolvedText = "✅ Resolved: these lines are now covered or no longer changed."
+
+// Plan lists the changes that bring litecov's review comments in line with
+// the current patch coverage.
+type Plan struct {
+	// Create holds new comments, to be posted as a single review.
+	Create []github.ReviewComment
+	// Update holds existing comments whose body must change, including
+	// resolved comments whose lines are uncovered again.
+	Update []github.ReviewComment
+	// Resolve holds existing comments that no longer apply, with their body
+	// replaced by ResolvedBody.
+	Resolve []github.ReviewComment
+	// ResolveThreads and ReopenThreads are the IDs of comments whose review
+	// threads should be resolved or reopened. ResolveThreads also lists
+	// comments resolved by earlier runs, in case their thread was reopened
+	// or resolving it failed.
+	ResolveThreads []int64
+	ReopenThreads  []int64
+	// Omitted counts uncovered ranges left out by the comment cap.
+	Omitted int
+}
+
+// Comments returns a comment for each range of consecutive uncovered added
+// lines in the patch, ordered by path and line. Patch files only list lines
+// added in the diff, so comments never land outside it.
+func Comments(patch *coverage.Patch) []github.ReviewComment {
+	if patch == nil {
+		return nil
+	}
+
+	var comments []github.ReviewComment
+	for _, pf := range patch.Files {
+		for _, r := range comment.GroupConsecutiveLines(pf.UncoveredLines) {
+			rc := github.ReviewComment{Path: pf.Path, Line: r.End, Body: commentBody(r)}
+			if r.Start != r.End {
+				rc.StartLine = r.Start
+			}
+			comments = append(comments, rc)
+		}
+	}
+
+	sort.SliceStable(comments, func(i, j int) bool {
+		if comments[i].Path != comments[j].Path {
+			return comments[i].Path < comments[j].Path
+		}
+		return comments[i].Line < comments[j].Line
+	})
+	return comments
+}
+
+// NewPlan compares the wanted comments with the existing review comments on
+// the pull request. Only comments carrying Marker are touched. At most
+// maxComments new comments are created; zero means no cap.
+func NewPlan(wanted, existing []github.ReviewComment, maxComments int) Plan {
+	var plan Plan
+
+	posted := make(map[string]github.ReviewComment)
+	for _, rc := range existing {
+		if !strings.Contains(rc.Body, Marker) {
+			continue
+		}
+		posted[key(rc)] = rc
+	}
+
+	seen := make(map[string]bool)
+	for _, rc := range wanted {
+		k := key(rc)
+		seen[k] = true
+		if old, ok := posted[k]; ok {
+			if IsResolved(old) {
+				plan.ReopenThreads = append(plan.ReopenThreads, old.ID)
+			}
+			if old.Body != rc.Body {
+				old.Body = rc.Body
+				plan.Update = append(plan.Update, old)
+			}
+			continue
+		}
+		if maxComments > 0 && len(plan.Create) >= maxComments {
+			plan.Omitted++
+			continue
+		}
+		plan.Create = append(plan.Create, rc)
+	}
+
+	for _, rc := range existing {
+		k := key(rc)
+		if seen[k] || posted[k].ID != rc.ID {
+			continue
+		}
+		plan.ResolveThreads = append(plan.ResolveThreads, rc.ID)
+		if IsResolved(rc) {
+			continue
+		}
+		rc.Body = ResolvedBody()
+		plan.Resolve = append(plan.Resolve, rc)
+	}
+
+	return plan
+}
+
+// ThreadChanges returns the IDs of the review threads the plan resolves and
+// reopens, leaving out threads already in the wanted state.
+func ThreadChanges(plan Plan, threads []github.ReviewThread) (resolve, reopen []string) {
+	byComment := make(map[int64]github.ReviewThread, len(threads))
+	for _, t := range threads {
+		byComment[t.CommentID] = t
+	}
+	for _, id := range plan.ResolveThreads {
+		if t, ok := byComment[id]; ok && !t.IsResolved {
+			resolve = append(resolve, t.ID)
+		}
+	}
+	for _, id := range plan.ReopenThreads {
+		if t, ok := byComment[id]; ok && t.IsResolved {
+			reopen = append(reopen, t.ID)
+		}
+	}
+	return resolve, reopen
+}
+
+// ReviewBody returns the summary posted with a review of new comments.
+func ReviewBody(plan Plan) string {
+	var sb strings.Builder
+	sb.WriteString(Marker)
+	sb.WriteString("\n")
+	sb.WriteString(fmt.Sprintf("**LiteCov:** %d uncovered %s in added lines.", len(plan.Create), plural(len(plan.Create), "range", "ranges")))
+	if plan.Omitted > 0 {
+		sb.WriteString(fmt.Sprintf(" %d more not shown.", plan.Omitted))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// ResolvedBody returns the body of a comment whose lines no longer need
+// tests.
+func ResolvedBody() string {
+	return Marker + "\n" + resolvedText
+}
+
+// IsResolved reports whether a litecov comment has already been resolved.
+func IsResolved(rc github.ReviewComment) bool {
+	return strings.Contains(rc.Body, resolvedText)
+}
+
+func commentBody(r comment.LineRange) string {
+	if r.Start == r.End {
+		return fmt.Sprintf("%s\n⚠️ Line %d is not covered by tests.", Marker, r.Start)
+	}
+	return fmt.Sprintf("%s\n⚠️ Lines %d-%d are not covered by tests.", Marker, r.Start, r.End)
+}
+
+// key identifies a comment by the lines it is attached to.
+func key(rc github.ReviewComment) string {
+	start := rc.StartLine
+	if start == 0 {
+		start = rc.Line
+	}
+	return fmt.Sprintf("%s:%d-%d", rc.Path, start, rc.Line)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
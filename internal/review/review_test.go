@@ -0,0 +1,127 @@
+package review
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/manashmandal/litecov/internal/coverage"
+	"github.com/manashmandal/litecov/internal/github"
+)
+
+func TestComments(t *testing.T) {
+	patch := &coverage.Patch{
+		Files: []coverage.PatchFile{
+			{Path: "src/b.go", UncoveredLines: []int{7}},
+			{Path: "src/a.go", UncoveredLines: []int{3, 4, 5, 10}},
+		},
+	}
+
+	comments := Comments(patch)
+	if len(comments) != 3 {
+		t.Fatalf("got %d comments, want 3: %+v", len(comments), comments)
+	}
+
+	expected := []struct {
+		path       string
+		start, end int
+		text       string
+	}{
+		{"src/a.go", 3, 5, "Lines 3-5 are not covered by tests."},
+		{"src/a.go", 0, 10, "Line 10 is not covered by tests."},
+		{"src/b.go", 0, 7, "Line 7 is not covered by tests."},
+	}
+	for i, e := range expected {
+		c := comments[i]
+		if c.Path != e.path || c.StartLine != e.start || c.Line != e.end {
+			t.Errorf("comments[%d] = %+v, want %s %d-%d", i, c, e.path, e.start, e.end)
+		}
+		if !strings.HasPrefix(c.Body, Marker) || !strings.Contains(c.Body, e.text) {
+			t.Errorf("comments[%d].Body = %q, want marker and %q", i, c.Body, e.text)
+		}
+	}
+}
+
+func TestComments_NilPatch(t *testing.T) {
+	if comments := Comments(nil); comments != nil {
+		t.Errorf("Comments(nil) = %v, want nil", comments)
+	}
+}
+
+func TestNewPlan(t *testing.T) {
+	wanted := Comments(&coverage.Patch{
+		Files: []coverage.PatchFile{
+			{Path: "a.go", UncoveredLines: []int{1, 2, 5, 9}},
+		},
+	})
+	existing := []github.ReviewComment{
+		// Still uncovered, unchanged
+		{ID: 1, Path: "a.go", StartLine: 1, Line: 2, Body: wanted[0].Body},
+		// Covered now
+		{ID: 2, Path: "a.go", Line: 7, Body: Marker + "\nLine 7 is not covered by tests."},
+		// Resolved earlier but uncovered again
+		{ID: 3, Path: "a.go", Line: 5, Body: ResolvedBody()},
+		// Resolved earlier and still fine
+		{ID: 4, Path: "a.go", Line: 20, Body: ResolvedBody()},
+		// Written by a reviewer, never touched
+		{ID: 5, Path: "a.go", Line: 30, Body: "Please add a test"},
+	}
+
+	plan := NewPlan(wanted, existing, 0)
+
+	if len(plan.Create) != 1 || plan.Create[0].Line != 9 {
+		t.Errorf("Create = %+v, want line 9", plan.Create)
+	}
+	if len(plan.Update) != 1 || plan.Update[0].ID != 3 || IsResolved(plan.Update[0]) {
+		t.Errorf("Update = %+v, want comment 3 reopened", plan.Update)
+	}
+	if len(plan.Resolve) != 1 || plan.Resolve[0].ID != 2 || !IsResolved(plan.Resolve[0]) {
+		t.Errorf("Resolve = %+v, want comment 2 resolved", plan.Resolve)
+	}
+	if !reflect.DeepEqual(plan.ResolveThreads, []int64{2, 4}) {
+		t.Errorf("ResolveThreads = %v, want [2 4]", plan.ResolveThreads)
+	}
+	if !reflect.DeepEqual(plan.ReopenThreads, []int64{3}) {
+		t.Errorf("ReopenThreads = %v, want [3]", plan.ReopenThreads)
+	}
+}
+
+func TestThreadChanges(t *testing.T) {
+	plan := Plan{ResolveThreads: []int64{2, 4, 6}, ReopenThreads: []int64{3, 5}}
+	threads := []github.ReviewThread{
+		{ID: "T1", CommentID: 1},
+		{ID: "T2", CommentID: 2},
+		{ID: "T3", CommentID: 3, IsResolved: true},
+		{ID: "T4", CommentID: 4, IsResolved: true},
+		{ID: "T5", CommentID: 5},
+	}
+
+	resolve, reopen := ThreadChanges(plan, threads)
+	if !reflect.DeepEqual(resolve, []string{"T2"}) {
+		t.Errorf("resolve = %v, want [T2]", resolve)
+	}
+	if !reflect.DeepEqual(reopen, []string{"T3"}) {
+		t.Errorf("reopen = %v, want [T3]", reopen)
+	}
+}
+
+func TestNewPlan_Cap(t *testing.T) {
+	wanted := Comments(&coverage.Patch{
+		Files: []coverage.PatchFile{
+			{Path: "a.go", UncoveredLines: []int{1, 3, 5, 7, 9}},
+		},
+	})
+
+	plan := NewPlan(wanted, nil, 2)
+	if len(plan.Create) != 2 || plan.Omitted != 3 {
+		t.Errorf("got %d created and %d omitted, want 2 and 3", len(plan.Create), plan.Omitted)
+	}
+
+	body := ReviewBody(plan)
+	if !strings.HasPrefix(body, Marker) {
+		t.Error("review body should start with marker")
+	}
+	if !strings.Contains(body, "2 uncovered ranges") || !strings.Contains(body, "3 more not shown") {
+		t.Errorf("unexpected review body: %q", body)
+	}
+}
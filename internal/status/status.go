@@ -0,0 +1,278 @@
+// Package status computes the commit statuses reported for a coverage run.
+package status
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/manashmandal/litecov/internal/coverage"
+	"github.com/manashmandal/litecov/internal/paths"
+)
+
+// Commit status states.
+const (
+	StateSuccess = "success"
+	StateFailure = "failure"
+)
+
+// MaxDescription is the longest description GitHub accepts for a commit status.
+const MaxDescription = 140
+
+// DefaultContext is the default prefix of status context names.
+const DefaultContext = "litecov"
+
+// Status is a single named commit status.
+type Status struct {
+	Context     string
+	State       string
+	Description string
+}
+
+// Component is a named group of files with its own status, e.g. "backend".
+type Component struct {
+	Name     string
+	Patterns []string
+	Target   float64
+}
+
+// reservedNames are status names components cannot take.
+var reservedNames = map[string]bool{
+	"project":                true,
+	"patch":                  true,
+	coverage.GateProjectDrop: true,
+	coverage.GateFileDrop:    true,
+	coverage.GateNewFile:     true,
+}
+
+// Config controls which statuses are reported and how they are named.
+type Config struct {
+	// Context is the project status context and the prefix of the others,
+	// e.g. "litecov" gives "litecov", "litecov/patch" and
+	// "litecov/<component>".
+	Context string
+	// ProjectContext and PatchContext override the full context names of
+	// the project and patch statuses.
+	ProjectContext string
+	PatchContext   string
+	ProjectTarget  float64
+	PatchTarget    float64
+	Components     []Component
+	// Informational reports every status as successful.
+	Informational bool
+	// Buckets, when set, prefix coverage descriptions with the indicator of
+	// the bucket the coverage falls in: its emoji, or its label when
+	// TextIndicators is set.
+	Buckets        coverage.Buckets
+	TextIndicators bool
+}
+
+// Input holds the coverage results the statuses are computed from.
+type Input struct {
+	Report *coverage.Report
+	// Base is the base report, or nil when there is no comparison.
+	Base *coverage.Report
+	// Patch is the coverage of added lines, or nil outside pull requests.
+	Patch      *coverage.Patch
+	Violations []coverage.Violation
+	Gates      []coverage.GateResult
+}
+
+// ParseComponents parses newline separated component definitions of the form
+// "name: pattern, pattern" with an optional target, "name@80: pattern".
+func ParseComponents(s string) ([]Component, error) {
+	var components []Component
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		idx := strings.Index(line, ":")
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid component %q: expected name: patterns", line)
+		}
+		c := Component{
+			Name:     strings.TrimSpace(line[:idx]),
+			Patterns: paths.SplitPatterns(line[idx+1:]),
+		}
+		if at := strings.Index(c.Name, "@"); at >= 0 {
+			target, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(c.Name[at+1:]), "%"), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid component %q: bad target", line)
+			}
+			c.Name = strings.TrimSpace(c.Name[:at])
+			c.Target = target
+		}
+		if c.Name == "" || len(c.Patterns) == 0 {
+			return nil, fmt.Errorf("invalid component %q: expected name: patterns", line)
+		}
+		if reservedNames[c.Name] {
+			return nil, fmt.Errorf("invalid component %q: %s is the name of a built-in status", line, c.Name)
+		}
+		components = append(components, c)
+	}
+	return components, nil
+}
+
+// Build computes the project, patch, component and ratchet gate statuses.
+func Build(cfg Config, in Input) []Status {
+	if in.Report == nil {
+		return nil
+	}
+	prefix := cfg.Context
+	if prefix == "" {
+		prefix = DefaultContext
+	}
+
+	var statuses []Status
+
+	project := Status{
+		Context:     firstNonEmpty(cfg.ProjectContext, prefix),
+		State:       StateSuccess,
+		Description: Describe(in.Report.Coverage, delta(in.Report, in.Base), cfg.ProjectTarget),
+	}
+	if len(in.Violations) > 0 {
+		project.State = StateFailure
+		if len(in.Violations) > 1 || in.Violations[0].Rule != "project" {
+			project.Description = ViolationsDescription(in.Report.Coverage, in.Violations)
+		}
+	}
+	project.Description = cfg.indicate(in.Report.Coverage, project.Description)
+	statuses = append(statuses, project)
+
+	if in.Patch != nil {
+		patch := Status{
+			Context: firstNonEmpty(cfg.PatchContext, prefix+"/patch"),
+			State:   StateSuccess,
+		}
+		if in.Patch.Total == 0 {
+			patch.Description = "No coverable lines changed"
+		} else {
+			pct := in.Patch.Coverage()
+			patch.Description = cfg.indicate(pct, Describe(pct, nil, cfg.PatchTarget))
+			if cfg.PatchTarget > 0 && pct < cfg.PatchTarget {
+				patch.State = StateFailure
+			}
+		}
+		statuses = append(statuses, patch)
+	}
+
+	for _, c := range cfg.Components {
+		filter := &paths.Filter{Include: c.Patterns}
+		head := in.Report.Subset(filter.Match)
+		var base *coverage.Report
+		if in.Base != nil {
+			base = in.Base.Subset(filter.Match)
+		}
+		st := Status{
+			Context:     prefix + "/" + c.Name,
+			State:       StateSuccess,
+			Description: Describe(head.Coverage, delta(head, base), c.Target),
+		}
+		if head.TotalLines == 0 {
+			st.Description = "No coverage data"
+		} else {
+			st.Description = cfg.indicate(head.Coverage, st.Description)
+			if c.Target > 0 && head.Coverage < c.Target {
+				st.State = StateFailure
+			}
+		}
+		statuses = append(statuses, st)
+	}
+
+	for _, g := range in.Gates {
+		st := Status{
+			Context:     prefix + "/" + g.Gate.Kind,
+			State:       StateSuccess,
+			Description: g.Description(),
+		}
+		if !g.Passed {
+			st.State = StateFailure
+		}
+		statuses = append(statuses, st)
+	}
+
+	for i := range statuses {
+		statuses[i].Description = truncate(statuses[i].Description)
+		if cfg.Informational {
+			statuses[i].State = StateSuccess
+		}
+	}
+	return statuses
+}
+
+// Describe formats a status description such as "78.20% (+1.30%) target 75.00%".
+// The delta is omitted when nil and the target when zero.
+func Describe(pct float64, delta *float64, target float64) string {
+	description := fmt.Sprintf("%.2f%%", pct)
+	if delta != nil {
+		description += fmt.Sprintf(" (%+.2f%%)", *delta)
+	}
+	if target > 0 {
+		description += fmt.Sprintf(" target %.2f%%", target)
+	}
+	return description
+}
+
+// indicate prefixes description with the indicator of pct's bucket.
+func (cfg Config) indicate(pct float64, description string) string {
+	if len(cfg.Buckets) == 0 {
+		return description
+	}
+	return cfg.Buckets.Indicator(pct, cfg.TextIndicators) + " " + description
+}
+
+// ViolationsDescription summarizes threshold violations, naming the
+// offending rules and files.
+func ViolationsDescription(pct float64, violations []coverage.Violation) string {
+	var names []string
+	for _, v := range violations {
+		switch {
+		case v.Rule == "project":
+			names = append(names, "project")
+		case len(v.Files) > 0:
+			names = append(names, v.Files...)
+		default:
+			names = append(names, v.Rule)
+		}
+	}
+	return truncate(fmt.Sprintf("%.2f%% coverage, below threshold: %s", pct, strings.Join(names, ", ")))
+}
+
+// Failed returns the statuses that are not successful.
+func Failed(statuses []Status) []Status {
+	var failed []Status
+	for _, s := range statuses {
+		if s.State != StateSuccess {
+			failed = append(failed, s)
+		}
+	}
+	return failed
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func delta(head, base *coverage.Report) *float64 {
+	if base == nil || base.TotalLines == 0 {
+		return nil
+	}
+	d := head.Coverage - base.Coverage
+	return &d
+}
+
+// truncate shortens description to MaxDescription characters, cutting on a
+// rune boundary so non-ASCII paths and indicators stay valid UTF-8.
+func truncate(description string) string {
+	runes := []rune(description)
+	if len(runes) <= MaxDescription {
+		return description
+	}
+	return string(runes[:MaxDescription-3]) + "..."
+}
@@ -0,0 +1,254 @@
+package status
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/manashmandal/litecov/internal/coverage"
+)
+
+func TestParseComponents(t *testing.T) {
+	input := `
+backend@80: internal/**, cmd/**
+frontend: web/**/*.{ts,tsx}
+`
+	components, err := ParseComponents(input)
+	if err != nil {
+		t.Fatalf("ParseComponents() error = %v", err)
+	}
+	want := []Component{
+		{Name: "backend", Patterns: []string{"internal/**", "cmd/**"}, Target: 80},
+		{Name: "frontend", Patterns: []string{"web/**/*.{ts,tsx}"}},
+	}
+	if !reflect.DeepEqual(components, want) {
+		t.Errorf("ParseComponents() = %+v, want %+v", components, want)
+	}
+}
+
+func TestParseComponents_Invalid(t *testing.T) {
+	for _, input := range []string{"backend", "backend:", ": internal/**", "backend@high: internal/**",
+		"project: internal/**", "patch@80: web/**", "new-file: internal/**"} {
+		if _, err := ParseComponents(input); err == nil {
+			t.Errorf("ParseComponents(%q) expected error", input)
+		}
+	}
+}
+
+func testReports() (*coverage.Report, *coverage.Report) {
+	head := &coverage.Report{
+		Files: []coverage.FileCoverage{
+			{Path: "internal/a.go", LinesCovered: 80, LinesTotal: 100},
+			{Path: "web/app.ts", LinesCovered: 40, LinesTotal: 100},
+		},
+	}
+	head.Calculate()
+	base := &coverage.Report{
+		Files: []coverage.FileCoverage{
+			{Path: "internal/a.go", LinesCovered: 70, LinesTotal: 100},
+			{Path: "web/app.ts", LinesCovered: 40, LinesTotal: 100},
+		},
+	}
+	base.Calculate()
+	return head, base
+}
+
+func findStatus(t *testing.T, statuses []Status, context string) Status {
+	t.Helper()
+	for _, s := range statuses {
+		if s.Context == context {
+			return s
+		}
+	}
+	t.Fatalf("status %q not found in %+v", context, statuses)
+	return Status{}
+}
+
+func TestBuild(t *testing.T) {
+	head, base := testReports()
+	cfg := Config{
+		ProjectTarget: 55,
+		PatchTarget:   90,
+		Components: []Component{
+			{Name: "backend", Patterns: []string{"internal/**"}, Target: 75},
+			{Name: "frontend", Patterns: []string{"web/**"}, Target: 50},
+		},
+	}
+	statuses := Build(cfg, Input{
+		Report: head,
+		Base:   base,
+		Patch:  &coverage.Patch{Covered: 8, Total: 10},
+		Gates: []coverage.GateResult{
+			{Gate: coverage.RatchetGate{Kind: coverage.GateProjectDrop, Limit: 0.5}, Passed: true, Actual: 5},
+		},
+	})
+
+	if len(statuses) != 5 {
+		t.Fatalf("got %d statuses, want 5: %+v", len(statuses), statuses)
+	}
+
+	project := findStatus(t, statuses, "litecov")
+	if project.State != StateSuccess || project.Description != "60.00% (+5.00%) target 55.00%" {
+		t.Errorf("unexpected project status: %+v", project)
+	}
+
+	patch := findStatus(t, statuses, "litecov/patch")
+	if patch.State != StateFailure || patch.Description != "80.00% target 90.00%" {
+		t.Errorf("unexpected patch status: %+v", patch)
+	}
+
+	backend := findStatus(t, statuses, "litecov/backend")
+	if backend.State != StateSuccess || backend.Description != "80.00% (+10.00%) target 75.00%" {
+		t.Errorf("unexpected backend status: %+v", backend)
+	}
+
+	frontend := findStatus(t, statuses, "litecov/frontend")
+	if frontend.State != StateFailure {
+		t.Errorf("frontend below target should fail: %+v", frontend)
+	}
+
+	gate := findStatus(t, statuses, "litecov/project-drop")
+	if gate.State != StateSuccess {
+		t.Errorf("passing gate should succeed: %+v", gate)
+	}
+}
+
+func TestBuild_ContextNames(t *testing.T) {
+	head, _ := testReports()
+	statuses := Build(Config{ProjectContext: "ci/coverage", PatchContext: "ci/patch-coverage"}, Input{
+		Report: head,
+		Patch:  &coverage.Patch{Covered: 1, Total: 2},
+	})
+
+	findStatus(t, statuses, "ci/coverage")
+	findStatus(t, statuses, "ci/patch-coverage")
+}
+
+func TestBuild_Violations(t *testing.T) {
+	head, _ := testReports()
+	statuses := Build(Config{Context: "coverage"}, Input{
+		Report: head,
+		Violations: []coverage.Violation{
+			{Rule: "web/**", Coverage: 40, Minimum: 50, Files: []string{"web/app.ts"}},
+		},
+	})
+
+	project := findStatus(t, statuses, "coverage")
+	if project.State != StateFailure {
+		t.Errorf("project with violations should fail: %+v", project)
+	}
+	if !strings.Contains(project.Description, "web/app.ts") {
+		t.Errorf("description should name offending file: %q", project.Description)
+	}
+}
+
+func TestBuild_ProjectViolationKeepsTargetDescription(t *testing.T) {
+	head, _ := testReports()
+	statuses := Build(Config{ProjectTarget: 75}, Input{
+		Report:     head,
+		Violations: []coverage.Violation{{Rule: "project", Coverage: 60, Minimum: 75}},
+	})
+
+	project := findStatus(t, statuses, "litecov")
+	if project.State != StateFailure || project.Description != "60.00% target 75.00%" {
+		t.Errorf("unexpected project status: %+v", project)
+	}
+}
+
+func TestBuild_Informational(t *testing.T) {
+	head, _ := testReports()
+	statuses := Build(Config{Informational: true, PatchTarget: 100}, Input{
+		Report:     head,
+		Patch:      &coverage.Patch{Covered: 0, Total: 10},
+		Violations: []coverage.Violation{{Rule: "project", Coverage: 60, Minimum: 75}},
+	})
+
+	if failed := Failed(statuses); len(failed) != 0 {
+		t.Errorf("informational mode should not report failures: %+v", failed)
+	}
+}
+
+func TestBuild_Buckets(t *testing.T) {
+	head, _ := testReports()
+	cfg := Config{
+		Buckets:        coverage.Buckets{{Min: 90, Label: "good"}, {Min: 0, Label: "poor"}},
+		TextIndicators: true,
+		Components:     []Component{{Name: "empty", Patterns: []string{"none/**"}}},
+	}
+	statuses := Build(cfg, Input{Report: head, Patch: &coverage.Patch{Covered: 10, Total: 10}})
+
+	project := findStatus(t, statuses, "litecov")
+	if want := "poor " + Describe(head.Coverage, nil, 0); project.Description != want {
+		t.Errorf("project description = %q, want %q", project.Description, want)
+	}
+	if patch := findStatus(t, statuses, "litecov/patch"); patch.Description != "good 100.00%" {
+		t.Errorf("patch description = %q", patch.Description)
+	}
+	if empty := findStatus(t, statuses, "litecov/empty"); empty.Description != "No coverage data" {
+		t.Errorf("component without data should not get an indicator: %q", empty.Description)
+	}
+}
+
+func TestBuild_EmptyPatch(t *testing.T) {
+	head, _ := testReports()
+	statuses := Build(Config{PatchTarget: 80}, Input{Report: head, Patch: &coverage.Patch{}})
+
+	patch := findStatus(t, statuses, "litecov/patch")
+	if patch.State != StateSuccess || patch.Description != "No coverable lines changed" {
+		t.Errorf("unexpected patch status: %+v", patch)
+	}
+}
+
+func TestBuild_NilReport(t *testing.T) {
+	if statuses := Build(Config{}, Input{}); statuses != nil {
+		t.Errorf("Build() with nil report = %v, want nil", statuses)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	delta := -1.5
+	tests := []struct {
+		pct      float64
+		delta    *float64
+		target   float64
+		expected string
+	}{
+		{78.2, nil, 0, "78.20%"},
+		{78.2, &delta, 0, "78.20% (-1.50%)"},
+		{78.2, &delta, 75, "78.20% (-1.50%) target 75.00%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := Describe(tt.pct, tt.delta, tt.target); got != tt.expected {
+				t.Errorf("Describe() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestViolationsDescription_Truncated(t *testing.T) {
+	var files []string
+	for i := 0; i < 30; i++ {
+		files = append(files, "internal/some/long/path/file.go")
+	}
+	description := ViolationsDescription(10, []coverage.Violation{{Rule: "file", Files: files}})
+	if len(description) != MaxDescription || !strings.HasSuffix(description, "...") {
+		t.Errorf("description should be truncated to %d characters, got %d", MaxDescription, len(description))
+	}
+}
+
+func TestTruncate_Multibyte(t *testing.T) {
+	description := "⚠️ " + strings.Repeat("répertoire/fichier.go, ", 10)
+	got := truncate(description)
+	if !utf8.ValidString(got) {
+		t.Errorf("truncate() returned invalid UTF-8: %q", got)
+	}
+	if n := utf8.RuneCountInString(got); n != MaxDescription || !strings.HasSuffix(got, "...") {
+		t.Errorf("truncate() has %d characters, want %d ending in ...", n, MaxDescription)
+	}
+	if short := "✅ 80.00%"; truncate(short) != short {
+		t.Errorf("truncate(%q) = %q, want it unchanged", short, truncate(short))
+	}
+}
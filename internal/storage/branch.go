@@ -0,0 +1,273 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/manashmandal/litecov/internal/coverage"
+	"github.com/manashmandal/litecov/internal/github"
+)
+
+// DefaultBranch is the orphan branch reports are committed to.
+const DefaultBranch = "litecov-data"
+
+// maxSaveAttempts bounds retries when another run updates the branch first.
+const maxSaveAttempts = 3
+
+// Branch stores reports as reports/<sha>.json on an orphan branch of the
+// repository, written through the Git Data API. No checkout is needed.
+type Branch struct {
+	Client *github.Client
+	Name   string
+
+	// reports and branches map commit SHAs and branch names to blob SHAs,
+	// and history maps history file names to blob SHAs. They are loaded on
+	// first use.
+	reports  map[string]string
+	branches map[string]string
+	history  map[string]string
+
+	// batching makes writes collect their files in pending until Flush.
+	batching bool
+	pending  []github.TreeEntry
+}
+
+var (
+	_ Storage = (*Branch)(nil)
+	_ History = (*Branch)(nil)
+)
+
+// NewBranch returns storage on the DefaultBranch of the client's repository.
+func NewBranch(client *github.Client) *Branch {
+	return &Branch{Client: client, Name: DefaultBranch}
+}
+
+// Save commits the report of commit sha to the data branch, creating the
+// branch as an orphan on first use.
+func (b *Branch) Save(sha, branch string, report *coverage.Report) error {
+	data, err := Encode(sha, report)
+	if err != nil {
+		return err
+	}
+	entries := []github.TreeEntry{
+		{Path: reportFile(sha), Mode: "100644", Type: "blob", Content: string(data)},
+		{Path: branchFile(branch), Mode: "100644", Type: "blob", Content: sha},
+	}
+
+	return b.write(entries, fmt.Sprintf("Coverage report for %s on %s", sha, branch))
+}
+
+// Put commits files, keyed by path, to the data branch. It is used for
+// artifacts such as badges that sit next to the stored reports.
+func (b *Branch) Put(files map[string][]byte, message string) error {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	entries := make([]github.TreeEntry, 0, len(paths))
+	for _, p := range paths {
+		entries = append(entries, github.TreeEntry{Path: p, Mode: "100644", Type: "blob", Content: string(files[p])})
+	}
+	return b.write(entries, message)
+}
+
+// Batch makes Save, Append and Put collect their files instead of
+// committing them, until Flush adds them all in a single commit.
+func (b *Branch) Batch() {
+	b.batching = true
+}
+
+// Flush commits the files collected since Batch, if any, and ends the batch.
+func (b *Branch) Flush(message string) error {
+	entries := b.pending
+	b.batching = false
+	b.pending = nil
+	if len(entries) == 0 {
+		return nil
+	}
+	return b.commit(entries, message)
+}
+
+// write commits entries, or collects them while batching.
+func (b *Branch) write(entries []github.TreeEntry, message string) error {
+	if b.batching {
+		b.pending = append(b.pending, entries...)
+		return nil
+	}
+	return b.commit(entries, message)
+}
+
+// commit adds entries to the data branch in a new commit, creating the
+// branch as an orphan on first use and retrying when another run moved it.
+func (b *Branch) commit(entries []github.TreeEntry, message string) error {
+	for attempt := 0; attempt < maxSaveAttempts; attempt++ {
+		parent, err := b.Client.GetBranchSHA(b.Name)
+		if err != nil && !errors.Is(err, github.ErrNotFound) {
+			return err
+		}
+
+		var baseTree string
+		var parents []string
+		if parent != "" {
+			if baseTree, err = b.Client.GetCommitTree(parent); err != nil {
+				return err
+			}
+			parents = []string{parent}
+		}
+
+		tree, err := b.Client.CreateTree(baseTree, entries)
+		if err != nil {
+			return err
+		}
+		commit, err := b.Client.CreateCommit(message, tree, parents)
+		if err != nil {
+			return err
+		}
+
+		if parent == "" {
+			err = b.Client.CreateBranch(b.Name, commit)
+		} else {
+			err = b.Client.UpdateBranch(b.Name, commit)
+		}
+		if errors.Is(err, github.ErrConflict) {
+			continue
+		}
+		if err == nil {
+			// Reload the index so new files can be found
+			b.reports = nil
+		}
+		return err
+	}
+	return fmt.Errorf("failed to update %s: %w", b.Name, github.ErrConflict)
+}
+
+// Load returns the report stored for commit sha, or ErrNotFound.
+func (b *Branch) Load(sha string) (*coverage.Report, error) {
+	if err := b.loadIndex(); err != nil {
+		return nil, err
+	}
+	blob, ok := b.reports[sha]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	data, err := b.Client.GetBlob(blob)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data)
+}
+
+// Latest returns the last report saved for branch.
+func (b *Branch) Latest(branch string) (*coverage.Report, string, error) {
+	if err := b.loadIndex(); err != nil {
+		return nil, "", err
+	}
+	blob, ok := b.branches[branch]
+	if !ok {
+		return nil, "", ErrNotFound
+	}
+	data, err := b.Client.GetBlob(blob)
+	if err != nil {
+		return nil, "", err
+	}
+	sha := strings.TrimSpace(string(data))
+	report, err := b.Load(sha)
+	return report, sha, err
+}
+
+// loadIndex lists the reports, branches and history directories of the data
+// branch. Each directory is listed on its own so the index never depends on
+// a recursive listing of the whole branch, which GitHub truncates.
+func (b *Branch) loadIndex() error {
+	if b.reports != nil {
+		return nil
+	}
+	root, err := b.Client.GetTree(b.Name)
+	if err != nil && !errors.Is(err, github.ErrNotFound) {
+		return err
+	}
+
+	reports := make(map[string]string)
+	branches := make(map[string]string)
+	history := make(map[string]string)
+	for _, dir := range root {
+		if dir.Type != "tree" {
+			continue
+		}
+		var add func(name, sha string)
+		switch dir.Path {
+		case reportsDir:
+			add = func(name, sha string) {
+				if path.Ext(name) == ".json" {
+					reports[strings.TrimSuffix(name, ".json")] = sha
+				}
+			}
+		case branchesDir:
+			add = func(name, sha string) {
+				if branch, err := url.PathUnescape(name); err == nil {
+					branches[branch] = sha
+				}
+			}
+		case historyDir:
+			add = func(name, sha string) { history[name] = sha }
+		default:
+			continue
+		}
+
+		entries, err := b.Client.GetTree(dir.SHA)
+		if err != nil {
+			return fmt.Errorf("listing %s: %w", dir.Path, err)
+		}
+		for _, e := range entries {
+			if e.Type == "blob" {
+				add(e.Path, e.SHA)
+			}
+		}
+	}
+	b.reports, b.branches, b.history = reports, branches, history
+	return nil
+}
+
+// Append commits a history entry to the data branch.
+func (b *Branch) Append(entry HistoryEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	name := historyFile(entry)
+	return b.write([]github.TreeEntry{
+		{Path: name, Mode: "100644", Type: "blob", Content: string(data)},
+	}, fmt.Sprintf("Coverage history for %s", entry.SHA))
+}
+
+// Recent returns up to n history entries, oldest first.
+func (b *Branch) Recent(n int) ([]HistoryEntry, error) {
+	if err := b.loadIndex(); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(b.history))
+	for name := range b.history {
+		names = append(names, name)
+	}
+
+	var entries []HistoryEntry
+	for _, name := range lastNames(names, n) {
+		data, err := b.Client.GetBlob(b.history[name])
+		if err != nil {
+			return nil, err
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,347 @@
+package storage
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/manashmandal/litecov/internal/github"
+)
+
+// fakeGit is an in-memory stand-in for the Git Data API of one repository.
+type fakeGit struct {
+	t        *testing.T
+	refs     map[string]string
+	commits  map[string]fakeCommit
+	trees    map[string]map[string]string // tree SHA -> path -> blob SHA
+	blobs    map[string]string
+	conflict int // number of ref updates to reject
+}
+
+type fakeCommit struct {
+	tree    string
+	parents []string
+}
+
+func newFakeGit(t *testing.T) *fakeGit {
+	return &fakeGit{
+		t:       t,
+		refs:    make(map[string]string),
+		commits: make(map[string]fakeCommit),
+		trees:   make(map[string]map[string]string),
+		blobs:   make(map[string]string),
+	}
+}
+
+func hash(parts ...interface{}) string {
+	return fmt.Sprintf("%x", sha1.Sum([]byte(fmt.Sprint(parts...))))
+}
+
+func (g *fakeGit) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p := strings.TrimPrefix(r.URL.Path, "/repos/o/r/git/")
+	respond := func(status int, v interface{}) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(v)
+	}
+
+	switch {
+	case r.Method == "GET" && strings.HasPrefix(p, "ref/heads/"):
+		sha, ok := g.refs[strings.TrimPrefix(p, "ref/heads/")]
+		if !ok {
+			respond(http.StatusNotFound, nil)
+			return
+		}
+		respond(http.StatusOK, map[string]interface{}{"object": map[string]string{"sha": sha}})
+
+	case r.Method == "GET" && strings.HasPrefix(p, "commits/"):
+		commit := g.commits[strings.TrimPrefix(p, "commits/")]
+		respond(http.StatusOK, map[string]interface{}{"tree": map[string]string{"sha": commit.tree}})
+
+	case r.Method == "GET" && strings.HasPrefix(p, "trees/"):
+		if r.URL.Query().Has("recursive") {
+			g.t.Errorf("recursive tree listing: %s", r.URL)
+		}
+		treeish := strings.TrimPrefix(p, "trees/")
+		if sha, ok := g.refs[treeish]; ok {
+			treeish = g.commits[sha].tree
+		}
+		files, ok := g.trees[treeish]
+		if !ok {
+			respond(http.StatusNotFound, nil)
+			return
+		}
+		respond(http.StatusOK, map[string]interface{}{"tree": g.list(files)})
+
+	case r.Method == "GET" && strings.HasPrefix(p, "blobs/"):
+		content := base64.StdEncoding.EncodeToString([]byte(g.blobs[strings.TrimPrefix(p, "blobs/")]))
+		respond(http.StatusOK, map[string]string{"content": content, "encoding": "base64"})
+
+	case r.Method == "POST" && p == "trees":
+		var req struct {
+			BaseTree string             `json:"base_tree"`
+			Tree     []github.TreeEntry `json:"tree"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		files := make(map[string]string)
+		for path, blob := range g.trees[req.BaseTree] {
+			files[path] = blob
+		}
+		for _, e := range req.Tree {
+			blob := hash(e.Content)
+			g.blobs[blob] = e.Content
+			files[e.Path] = blob
+		}
+		sha := hash(files)
+		g.trees[sha] = files
+		respond(http.StatusCreated, map[string]string{"sha": sha})
+
+	case r.Method == "POST" && p == "commits":
+		var req struct {
+			Tree    string   `json:"tree"`
+			Parents []string `json:"parents"`
+			Message string   `json:"message"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		sha := hash(req.Tree, req.Parents, req.Message)
+		g.commits[sha] = fakeCommit{tree: req.Tree, parents: req.Parents}
+		respond(http.StatusCreated, map[string]string{"sha": sha})
+
+	case r.Method == "POST" && p == "refs":
+		var req struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		branch := strings.TrimPrefix(req.Ref, "refs/heads/")
+		if _, exists := g.refs[branch]; exists {
+			respond(http.StatusUnprocessableEntity, nil)
+			return
+		}
+		g.refs[branch] = req.SHA
+		respond(http.StatusCreated, nil)
+
+	case r.Method == "PATCH" && strings.HasPrefix(p, "refs/heads/"):
+		var req struct {
+			SHA string `json:"sha"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		branch := strings.TrimPrefix(p, "refs/heads/")
+		if g.conflict > 0 {
+			g.conflict--
+			respond(http.StatusUnprocessableEntity, nil)
+			return
+		}
+		parents := g.commits[req.SHA].parents
+		if len(parents) != 1 || parents[0] != g.refs[branch] {
+			respond(http.StatusUnprocessableEntity, nil)
+			return
+		}
+		g.refs[branch] = req.SHA
+		respond(http.StatusOK, nil)
+
+	default:
+		g.t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		respond(http.StatusNotFound, nil)
+	}
+}
+
+// list returns the top level entries of a tree, registering each directory
+// as a subtree that can be listed by its SHA.
+func (g *fakeGit) list(files map[string]string) []github.TreeEntry {
+	var entries []github.TreeEntry
+	dirs := make(map[string]map[string]string)
+	for path, blob := range files {
+		dir, rest, nested := strings.Cut(path, "/")
+		if !nested {
+			entries = append(entries, github.TreeEntry{Path: path, Type: "blob", SHA: blob})
+			continue
+		}
+		if dirs[dir] == nil {
+			dirs[dir] = make(map[string]string)
+		}
+		dirs[dir][rest] = blob
+	}
+	for dir, sub := range dirs {
+		sha := hash(sub)
+		g.trees[sha] = sub
+		entries = append(entries, github.TreeEntry{Path: dir, Type: "tree", SHA: sha})
+	}
+	return entries
+}
+
+func newTestBranch(t *testing.T) (*Branch, *fakeGit) {
+	git := newFakeGit(t)
+	server := httptest.NewServer(git)
+	t.Cleanup(server.Close)
+	client := &github.Client{Token: "test", Owner: "o", Repo: "r", BaseURL: server.URL}
+	return NewBranch(client), git
+}
+
+func TestBranch_SaveAndLoad(t *testing.T) {
+	b, git := newTestBranch(t)
+
+	if _, err := b.Load("first"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Load() before save error = %v, want ErrNotFound", err)
+	}
+
+	if err := b.Save("first", "main", testReport()); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	root := git.commits[git.refs[DefaultBranch]]
+	if len(root.parents) != 0 {
+		t.Errorf("first commit should be an orphan, has parents %v", root.parents)
+	}
+
+	if err := b.Save("second", "main", testReport()); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	head := git.commits[git.refs[DefaultBranch]]
+	if len(head.parents) != 1 {
+		t.Errorf("second commit should have one parent, has %v", head.parents)
+	}
+	if len(git.trees[head.tree]) != 3 {
+		t.Errorf("data branch should hold 2 reports and a branch file, has %v", git.trees[head.tree])
+	}
+
+	report, err := b.Load("first")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if report.TotalLines != 6 {
+		t.Errorf("TotalLines = %d, want 6", report.TotalLines)
+	}
+}
+
+func TestBranch_SaveRetriesConflict(t *testing.T) {
+	b, git := newTestBranch(t)
+	if err := b.Save("first", "main", testReport()); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	git.conflict = 1
+	if err := b.Save("second", "main", testReport()); err != nil {
+		t.Fatalf("Save() should retry after a conflict, error = %v", err)
+	}
+
+	git.conflict = maxSaveAttempts
+	if err := b.Save("third", "main", testReport()); !errors.Is(err, github.ErrConflict) {
+		t.Errorf("Save() error = %v, want ErrConflict", err)
+	}
+}
+
+func TestBranch_Latest(t *testing.T) {
+	b, _ := newTestBranch(t)
+	if _, _, err := b.Latest("main"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Latest() before save error = %v, want ErrNotFound", err)
+	}
+
+	b.Save("first", "main", testReport())
+	b.Save("second", "main", testReport())
+	b.Save("third", "release/1.0", testReport())
+
+	for branch, want := range map[string]string{"main": "second", "release/1.0": "third"} {
+		report, sha, err := b.Latest(branch)
+		if err != nil {
+			t.Fatalf("Latest(%q) error = %v", branch, err)
+		}
+		if sha != want || report == nil {
+			t.Errorf("Latest(%q) = %q, want %q", branch, sha, want)
+		}
+	}
+}
+
+func TestBranch_FindNearest(t *testing.T) {
+	b, _ := newTestBranch(t)
+	if err := b.Save("older", "main", testReport()); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	report, sha, err := FindNearest(b, []string{"merge-base", "parent", "older", "oldest"})
+	if err != nil {
+		t.Fatalf("FindNearest() error = %v", err)
+	}
+	if sha != "older" || report == nil {
+		t.Errorf("FindNearest() found %q, want older", sha)
+	}
+
+	if _, _, err := FindNearest(b, []string{"unknown"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindNearest() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestBranch_Put(t *testing.T) {
+	b, git := newTestBranch(t)
+	if err := b.Save("first", "main", testReport()); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	err := b.Put(map[string][]byte{
+		"badges/coverage.svg": []byte("<svg/>"),
+		"badges/patch.svg":    []byte("<svg></svg>"),
+	}, "Update badges")
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	tree := git.trees[git.commits[git.refs[DefaultBranch]].tree]
+	if len(tree) != 4 {
+		t.Fatalf("data branch should keep the report and add 2 badges, has %v", tree)
+	}
+	if got := git.blobs[tree["badges/coverage.svg"]]; got != "<svg/>" {
+		t.Errorf("badges/coverage.svg = %q", got)
+	}
+	if _, err := b.Load("first"); err != nil {
+		t.Errorf("Load() after Put() error = %v", err)
+	}
+}
+
+func TestBranch_Batch(t *testing.T) {
+	b, git := newTestBranch(t)
+	b.Batch()
+
+	if err := b.Save("first", "main", testReport()); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := b.Append(HistoryEntry{SHA: "first", Coverage: 50}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	if err := b.Put(map[string][]byte{"badges/coverage.svg": []byte("<svg/>")}, "Update badges"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if len(git.commits) != 0 {
+		t.Fatalf("batched writes should not commit before Flush, got %d commits", len(git.commits))
+	}
+
+	if err := b.Flush("Coverage data for first"); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(git.commits) != 1 {
+		t.Errorf("Flush() should make one commit, got %d", len(git.commits))
+	}
+	if tree := git.trees[git.commits[git.refs[DefaultBranch]].tree]; len(tree) != 4 {
+		t.Errorf("data branch should hold a report, a branch file, a history entry and a badge, has %v", tree)
+	}
+	if _, err := b.Load("first"); err != nil {
+		t.Errorf("Load() after Flush() error = %v", err)
+	}
+	if entries, err := b.Recent(10); err != nil || len(entries) != 1 {
+		t.Errorf("Recent() = %v, %v, want 1 entry", entries, err)
+	}
+
+	// Writes after Flush are committed right away again
+	if err := b.Save("second", "main", testReport()); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if len(git.commits) != 2 {
+		t.Errorf("Save() after Flush() should commit, got %d commits", len(git.commits))
+	}
+	if err := b.Flush("nothing"); err != nil || len(git.commits) != 2 {
+		t.Errorf("Flush() without writes should not commit, got %v and %d commits", err, len(git.commits))
+	}
+}
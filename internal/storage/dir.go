@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/manashmandal/litecov/internal/coverage"
+)
+
+// DefaultDir is the directory used by the local directory backend.
+const DefaultDir = ".litecov"
+
+// Dir stores reports in a local directory, to be persisted between runs by
+// actions/cache or any other CI cache.
+type Dir struct {
+	Path string
+}
+
+var (
+	_ Storage = (*Dir)(nil)
+	_ History = (*Dir)(nil)
+)
+
+// NewDir returns storage in the directory at path.
+func NewDir(path string) *Dir {
+	return &Dir{Path: path}
+}
+
+// Save writes the report of commit sha and marks it as the latest of branch.
+func (d *Dir) Save(sha, branch string, report *coverage.Report) error {
+	data, err := Encode(sha, report)
+	if err != nil {
+		return err
+	}
+	if err := d.write(reportFile(sha), data); err != nil {
+		return err
+	}
+	return d.write(branchFile(branch), []byte(sha+"\n"))
+}
+
+// Load returns the report of commit sha, or ErrNotFound.
+func (d *Dir) Load(sha string) (*coverage.Report, error) {
+	data, err := d.read(reportFile(sha))
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data)
+}
+
+// Latest returns the last report saved for branch.
+func (d *Dir) Latest(branch string) (*coverage.Report, string, error) {
+	data, err := d.read(branchFile(branch))
+	if err != nil {
+		return nil, "", err
+	}
+	sha := strings.TrimSpace(string(data))
+	report, err := d.Load(sha)
+	return report, sha, err
+}
+
+// write replaces a file atomically so a cancelled job never leaves a
+// truncated report behind.
+func (d *Dir) write(name string, data []byte) error {
+	path := filepath.Join(d.Path, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (d *Dir) read(name string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(d.Path, filepath.FromSlash(name)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// Append writes a history entry to its own file.
+func (d *Dir) Append(entry HistoryEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return d.write(historyFile(entry), data)
+}
+
+// Recent returns up to n history entries, oldest first.
+func (d *Dir) Recent(n int) ([]HistoryEntry, error) {
+	files, err := os.ReadDir(filepath.Join(d.Path, historyDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, f := range files {
+		if !f.IsDir() && filepath.Ext(f.Name()) == ".json" {
+			names = append(names, f.Name())
+		}
+	}
+
+	var entries []HistoryEntry
+	for _, name := range lastNames(names, n) {
+		data, err := d.read(historyDir + "/" + name)
+		if err != nil {
+			return nil, err
+		}
+		var entry HistoryEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDir_SaveAndLoad(t *testing.T) {
+	d := NewDir(filepath.Join(t.TempDir(), "cache"))
+
+	if _, err := d.Load("abc123"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Load() before save error = %v, want ErrNotFound", err)
+	}
+	if _, _, err := d.Latest("main"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Latest() before save error = %v, want ErrNotFound", err)
+	}
+
+	if err := d.Save("abc123", "feature/x", testReport()); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	report, err := d.Load("abc123")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if report.TotalLines != 6 {
+		t.Errorf("TotalLines = %d, want 6", report.TotalLines)
+	}
+
+	_, sha, err := d.Latest("feature/x")
+	if err != nil || sha != "abc123" {
+		t.Errorf("Latest() = %q, %v", sha, err)
+	}
+
+	if _, err := os.Stat(filepath.Join(d.Path, "branches", "feature%2Fx")); err != nil {
+		t.Errorf("branch names with slashes should be escaped: %v", err)
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(d.Path, "reports", ".tmp-*"))
+	if len(leftovers) != 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+}
+
+func TestFindNearest(t *testing.T) {
+	m := NewMemory()
+	m.Save("older", "main", testReport())
+
+	report, sha, err := FindNearest(m, []string{"merge-base", "parent", "older"})
+	if err != nil || sha != "older" || report == nil {
+		t.Errorf("FindNearest() = %q, %v", sha, err)
+	}
+
+	if _, _, err := FindNearest(m, nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("FindNearest() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	m := NewMemory()
+	m.Save("a", "main", testReport())
+	m.Save("b", "main", testReport())
+
+	if m.Saves != 2 {
+		t.Errorf("Saves = %d, want 2", m.Saves)
+	}
+	if _, sha, _ := m.Latest("main"); sha != "b" {
+		t.Errorf("Latest() = %q, want b", sha)
+	}
+	if _, err := m.Load("c"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load() error = %v, want ErrNotFound", err)
+	}
+}
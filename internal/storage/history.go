@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/manashmandal/litecov/internal/coverage"
+)
+
+// historyDir holds one file per history entry, named so that sorting the
+// names sorts the entries by time.
+const historyDir = "history"
+
+// History is an append-only record of default branch coverage.
+type History interface {
+	// Append records an entry.
+	Append(entry HistoryEntry) error
+	// Recent returns up to n of the latest entries, oldest first.
+	Recent(n int) ([]HistoryEntry, error)
+}
+
+// HistoryEntry summarizes the coverage of one commit.
+type HistoryEntry struct {
+	SHA      string        `json:"sha"`
+	Time     time.Time     `json:"time"`
+	Covered  int           `json:"covered"`
+	Total    int           `json:"total"`
+	Coverage float64       `json:"coverage"`
+	Files    []HistoryFile `json:"files,omitempty"`
+}
+
+// HistoryFile summarizes the coverage of one file in a history entry.
+type HistoryFile struct {
+	Path    string `json:"path"`
+	Covered int    `json:"covered"`
+	Total   int    `json:"total"`
+}
+
+// Coverage returns the percentage of the file's lines that are covered.
+func (f HistoryFile) Coverage() float64 {
+	if f.Total == 0 {
+		return 0
+	}
+	return float64(f.Covered) / float64(f.Total) * 100
+}
+
+// NewHistoryEntry summarizes a report for the history.
+func NewHistoryEntry(sha string, t time.Time, report *coverage.Report) HistoryEntry {
+	entry := HistoryEntry{
+		SHA:      sha,
+		Time:     t.UTC(),
+		Covered:  report.TotalCovered,
+		Total:    report.TotalLines,
+		Coverage: report.Coverage,
+	}
+	for _, f := range report.Files {
+		entry.Files = append(entry.Files, HistoryFile{Path: f.Path, Covered: f.LinesCovered, Total: f.LinesTotal})
+	}
+	sort.Slice(entry.Files, func(i, j int) bool { return entry.Files[i].Path < entry.Files[j].Path })
+	return entry
+}
+
+// File returns the summary of the file at path, if the entry has one.
+func (e HistoryEntry) File(path string) (HistoryFile, bool) {
+	for _, f := range e.Files {
+		if f.Path == path {
+			return f, true
+		}
+	}
+	return HistoryFile{}, false
+}
+
+// historyFile names an entry by its zero-padded Unix time and commit.
+func historyFile(entry HistoryEntry) string {
+	return path.Join(historyDir, fmt.Sprintf("%012d-%s.json", entry.Time.Unix(), entry.SHA))
+}
+
+// lastNames sorts history file names and returns the last n.
+func lastNames(names []string, n int) []string {
+	sort.Strings(names)
+	if n > 0 && len(names) > n {
+		names = names[len(names)-n:]
+	}
+	return names
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewHistoryEntry(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	entry := NewHistoryEntry("abc123", at, testReport())
+
+	if entry.SHA != "abc123" || entry.Covered != 4 || entry.Total != 6 {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if entry.Time.Location() != time.UTC {
+		t.Errorf("time should be stored in UTC, got %v", entry.Time)
+	}
+	if len(entry.Files) != 2 || entry.Files[0].Path != "src/a.go" {
+		t.Errorf("files should be sorted by path: %+v", entry.Files)
+	}
+
+	f, ok := entry.File("src/b.go")
+	if !ok || f.Coverage() != 50 {
+		t.Errorf("File(src/b.go) = %+v, %v", f, ok)
+	}
+	if _, ok := entry.File("missing.go"); ok {
+		t.Error("File() should report missing files")
+	}
+}
+
+func historyEntries(n int) []HistoryEntry {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var entries []HistoryEntry
+	for i := 0; i < n; i++ {
+		entries = append(entries, HistoryEntry{
+			SHA:      fmt.Sprintf("sha%d", i),
+			Time:     start.Add(time.Duration(i) * time.Hour),
+			Coverage: float64(50 + i),
+		})
+	}
+	return entries
+}
+
+func testHistory(t *testing.T, h History) {
+	t.Helper()
+
+	entries, err := h.Recent(5)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("Recent() on empty history = %v, %v", entries, err)
+	}
+
+	for _, e := range historyEntries(12) {
+		if err := h.Append(e); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	entries, err = h.Recent(5)
+	if err != nil {
+		t.Fatalf("Recent() error = %v", err)
+	}
+	if len(entries) != 5 {
+		t.Fatalf("got %d entries, want 5", len(entries))
+	}
+	if entries[0].SHA != "sha7" || entries[4].SHA != "sha11" {
+		t.Errorf("Recent() = %s..%s, want sha7..sha11", entries[0].SHA, entries[4].SHA)
+	}
+
+	all, _ := h.Recent(0)
+	if len(all) != 12 {
+		t.Errorf("Recent(0) returned %d entries, want all 12", len(all))
+	}
+}
+
+func TestDir_History(t *testing.T) {
+	testHistory(t, NewDir(filepath.Join(t.TempDir(), "cache")))
+}
+
+func TestBranch_History(t *testing.T) {
+	b, _ := newTestBranch(t)
+	testHistory(t, b)
+}
+
+func TestMemory_History(t *testing.T) {
+	testHistory(t, NewMemory())
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"github.com/manashmandal/litecov/internal/coverage"
+)
+
+// Memory keeps reports in memory. It is meant for tests of code that takes
+// a Storage.
+type Memory struct {
+	Reports  map[string]*coverage.Report
+	Branches map[string]string
+	// Saves counts calls to Save.
+	Saves int
+	// Entries holds the history in the order it was appended.
+	Entries []HistoryEntry
+}
+
+var (
+	_ Storage = (*Memory)(nil)
+	_ History = (*Memory)(nil)
+)
+
+// NewMemory returns empty in-memory storage.
+func NewMemory() *Memory {
+	return &Memory{
+		Reports:  make(map[string]*coverage.Report),
+		Branches: make(map[string]string),
+	}
+}
+
+// Save records the report of commit sha as the latest of branch.
+func (m *Memory) Save(sha, branch string, report *coverage.Report) error {
+	m.Saves++
+	m.Reports[sha] = report
+	m.Branches[branch] = sha
+	return nil
+}
+
+// Load returns the report of commit sha, or ErrNotFound.
+func (m *Memory) Load(sha string) (*coverage.Report, error) {
+	report, ok := m.Reports[sha]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return report, nil
+}
+
+// Latest returns the last report saved for branch.
+func (m *Memory) Latest(branch string) (*coverage.Report, string, error) {
+	sha, ok := m.Branches[branch]
+	if !ok {
+		return nil, "", ErrNotFound
+	}
+	report, err := m.Load(sha)
+	return report, sha, err
+}
+
+// Append records a history entry.
+func (m *Memory) Append(entry HistoryEntry) error {
+	m.Entries = append(m.Entries, entry)
+	return nil
+}
+
+// Recent returns up to n of the latest history entries, oldest first.
+func (m *Memory) Recent(n int) ([]HistoryEntry, error) {
+	if n > 0 && len(m.Entries) > n {
+		return m.Entries[len(m.Entries)-n:], nil
+	}
+	return m.Entries, nil
+}
@@ -0,0 +1,123 @@
+// Package storage persists coverage reports keyed by commit SHA, so pull
+// requests can be compared with the report of their base commit.
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/manashmandal/litecov/internal/coverage"
+)
+
+// ErrNotFound is returned when no report is stored for a commit or branch.
+var ErrNotFound = errors.New("no stored coverage report")
+
+// Storage saves and loads reports by commit SHA and branch.
+type Storage interface {
+	// Save stores the report of commit sha, made on branch, and makes it
+	// the latest report of that branch.
+	Save(sha, branch string, report *coverage.Report) error
+	// Load returns the report of commit sha, or ErrNotFound.
+	Load(sha string) (*coverage.Report, error)
+	// Latest returns the most recently saved report of branch along with
+	// its commit, or ErrNotFound.
+	Latest(branch string) (*coverage.Report, string, error)
+}
+
+// FindNearest returns the report of the first candidate commit that has
+// one, along with that commit. Candidates are usually a merge base followed
+// by its ancestors, newest first.
+func FindNearest(s Storage, candidates []string) (*coverage.Report, string, error) {
+	for _, sha := range candidates {
+		report, err := s.Load(sha)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		return report, sha, nil
+	}
+	return nil, "", ErrNotFound
+}
+
+// Layout shared by the file based backends: one report per commit and one
+// file per branch naming its latest commit.
+const (
+	reportsDir  = "reports"
+	branchesDir = "branches"
+)
+
+func reportFile(sha string) string {
+	return reportsDir + "/" + sha + ".json"
+}
+
+// branchFile escapes the branch name so "feature/x" stays a single file.
+func branchFile(branch string) string {
+	return branchesDir + "/" + url.PathEscape(branch)
+}
+
+// formatVersion is bumped when the stored JSON changes incompatibly.
+const formatVersion = 1
+
+type storedReport struct {
+	Version int          `json:"version"`
+	SHA     string       `json:"sha"`
+	Files   []storedFile `json:"files"`
+}
+
+type storedFile struct {
+	Path           string `json:"path"`
+	LinesCovered   int    `json:"lines_covered"`
+	LinesTotal     int    `json:"lines_total"`
+	CoveredLines   []int  `json:"covered_lines,omitempty"`
+	UncoveredLines []int  `json:"uncovered_lines,omitempty"`
+	ExcludedLines  int    `json:"excluded_lines,omitempty"`
+}
+
+// Encode serializes a report as JSON with files sorted by path, so the same
+// report always produces the same bytes.
+func Encode(sha string, report *coverage.Report) ([]byte, error) {
+	stored := storedReport{Version: formatVersion, SHA: sha, Files: []storedFile{}}
+	for _, f := range report.Files {
+		stored.Files = append(stored.Files, storedFile{
+			Path:           f.Path,
+			LinesCovered:   f.LinesCovered,
+			LinesTotal:     f.LinesTotal,
+			CoveredLines:   f.CoveredLines,
+			UncoveredLines: f.UncoveredLines,
+			ExcludedLines:  f.ExcludedLines,
+		})
+	}
+	sort.Slice(stored.Files, func(i, j int) bool { return stored.Files[i].Path < stored.Files[j].Path })
+	return json.MarshalIndent(stored, "", "  ")
+}
+
+// Decode parses a report written by Encode and recalculates its totals.
+func Decode(data []byte) (*coverage.Report, error) {
+	var stored storedReport
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, err
+	}
+	if stored.Version != formatVersion {
+		return nil, fmt.Errorf("unsupported stored report version %d", stored.Version)
+	}
+
+	report := &coverage.Report{}
+	for _, f := range stored.Files {
+		report.Files = append(report.Files, coverage.FileCoverage{
+			Path:           f.Path,
+			LinesCovered:   f.LinesCovered,
+			LinesTotal:     f.LinesTotal,
+			CoveredLines:   f.CoveredLines,
+			UncoveredLines: f.UncoveredLines,
+			ExcludedLines:  f.ExcludedLines,
+		})
+		report.ExcludedLines += f.ExcludedLines
+	}
+	report.Calculate()
+	return report, nil
+}
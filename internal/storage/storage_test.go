@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/manashmandal/litecov/internal/coverage"
+)
+
+func testReport() *coverage.Report {
+	report := &coverage.Report{
+		Files: []coverage.FileCoverage{
+			{Path: "src/b.go", LinesCovered: 1, LinesTotal: 2, CoveredLines: []int{1}, UncoveredLines: []int{2}},
+			{Path: "src/a.go", LinesCovered: 3, LinesTotal: 4, CoveredLines: []int{1, 2, 3}, UncoveredLines: []int{4}, ExcludedLines: 2},
+		},
+	}
+	report.Calculate()
+	report.ExcludedLines = 2
+	return report
+}
+
+func TestEncodeDecode(t *testing.T) {
+	data, err := Encode("abc123", testReport())
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	report, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if report.TotalCovered != 4 || report.TotalLines != 6 || report.ExcludedLines != 2 {
+		t.Errorf("totals = %d/%d excluded %d", report.TotalCovered, report.TotalLines, report.ExcludedLines)
+	}
+	if report.Files[0].Path != "src/a.go" {
+		t.Errorf("files should be sorted by path, got %s first", report.Files[0].Path)
+	}
+	if !reflect.DeepEqual(report.Files[0].CoveredLines, []int{1, 2, 3}) {
+		t.Errorf("CoveredLines = %v", report.Files[0].CoveredLines)
+	}
+}
+
+func TestEncode_Deterministic(t *testing.T) {
+	first, _ := Encode("abc123", testReport())
+
+	reversed := testReport()
+	reversed.Files[0], reversed.Files[1] = reversed.Files[1], reversed.Files[0]
+	second, _ := Encode("abc123", reversed)
+
+	if !bytes.Equal(first, second) {
+		t.Error("file order should not change the encoded report")
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	for _, data := range []string{"not json", `{"version": 99, "files": []}`} {
+		if _, err := Decode([]byte(data)); err == nil {
+			t.Errorf("Decode(%q) expected error", data)
+		}
+	}
+}